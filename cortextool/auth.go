@@ -0,0 +1,194 @@
+package cortextool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/prometheus/common/config"
+	"github.com/prometheus/common/sigv4"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// headerTransport sets the provider's extra headers on every request, overriding the ones set by
+// the cortextool client such as X-Scope-OrgID or Authorization.
+type headerTransport struct {
+	next    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// getHTTPHeaders returns the headers to set on every request: http_headers, falling back to the
+// CORTEXTOOL_HTTP_HEADERS environment variable holding a JSON object.
+func getHTTPHeaders(d *schema.ResourceData) (map[string]string, error) {
+	headers := stringValueMap(d.Get("http_headers").(map[string]any))
+	if envHeaders := os.Getenv("CORTEXTOOL_HTTP_HEADERS"); len(headers) == 0 && envHeaders != "" {
+		var parsed map[string]string
+		if err := json.Unmarshal([]byte(envHeaders), &parsed); err != nil {
+			return nil, fmt.Errorf("CORTEXTOOL_HTTP_HEADERS must be a JSON object of header names to values: %w", err)
+		}
+		for name, value := range parsed {
+			headers[name] = value
+		}
+	}
+	return headers, nil
+}
+
+func oauth2Schema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"token_url": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "URL of the token endpoint.",
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
+			"client_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Client ID.",
+			},
+			"client_secret": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("CORTEXTOOL_OAUTH2_CLIENT_SECRET", nil),
+				Description: "Client secret. May alternatively be set via the `CORTEXTOOL_OAUTH2_CLIENT_SECRET` environment variable.",
+			},
+			"scopes": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Scopes to request.",
+			},
+			"audience": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Audience to request the token for, sent as the `audience` parameter.",
+			},
+		},
+	}
+}
+
+// getOAuth2Config returns the client credentials configuration of the oauth2 block, or nil when it is not set.
+func getOAuth2Config(d *schema.ResourceData) *clientcredentials.Config {
+	blocks := d.Get("oauth2").([]any)
+	if len(blocks) == 0 || blocks[0] == nil {
+		return nil
+	}
+	block := blocks[0].(map[string]any)
+
+	config := &clientcredentials.Config{
+		ClientID:     block["client_id"].(string),
+		ClientSecret: block["client_secret"].(string),
+		TokenURL:     block["token_url"].(string),
+	}
+	for _, scope := range block["scopes"].([]any) {
+		config.Scopes = append(config.Scopes, scope.(string))
+	}
+	if audience := block["audience"].(string); audience != "" {
+		config.EndpointParams = url.Values{"audience": []string{audience}}
+	}
+	return config
+}
+
+// newOAuth2Transport authenticates the requests with tokens fetched from the token endpoint, through
+// tokenTransport, and refreshed once expired.
+func newOAuth2Transport(next http.RoundTripper, config *clientcredentials.Config, tokenTransport http.RoundTripper) http.RoundTripper {
+	// The token source outlives the provider's configuration, it must not use its context
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: tokenTransport})
+	return &oauth2.Transport{
+		Source: config.TokenSource(ctx),
+		Base:   next,
+	}
+}
+
+func sigv4Schema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "AWS region of the ruler.",
+			},
+			"role_arn": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "ARN of the role to assume to sign the requests.",
+			},
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Named AWS profile to take the credentials from.",
+			},
+			"access_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "AWS access key, the default credentials chain is used when not set.",
+				RequiredWith: []string{"sigv4.0.secret_key"},
+			},
+			"secret_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				Description:  "AWS secret key.",
+				RequiredWith: []string{"sigv4.0.access_key"},
+			},
+		},
+	}
+}
+
+// getSigV4Config returns the configuration of the sigv4 block, or nil when it is not set.
+func getSigV4Config(d *schema.ResourceData) *sigv4.SigV4Config {
+	blocks := d.Get("sigv4").([]any)
+	if len(blocks) == 0 || blocks[0] == nil {
+		return nil
+	}
+	block := blocks[0].(map[string]any)
+
+	return &sigv4.SigV4Config{
+		Region:    block["region"].(string),
+		AccessKey: block["access_key"].(string),
+		SecretKey: config.Secret(block["secret_key"].(string)),
+		Profile:   block["profile"].(string),
+		RoleARN:   block["role_arn"].(string),
+	}
+}
+
+// sigv4Transport signs the requests for Amazon Managed Service for Prometheus.
+type sigv4Transport struct {
+	signer http.RoundTripper
+}
+
+func newSigV4Transport(next http.RoundTripper, sigv4Config *sigv4.SigV4Config) (*sigv4Transport, error) {
+	if err := sigv4Config.Validate(); err != nil {
+		return nil, err
+	}
+	signer, err := sigv4.NewSigV4RoundTripper(sigv4Config, next)
+	if err != nil {
+		return nil, err
+	}
+	return &sigv4Transport{signer: signer}, nil
+}
+
+func (t *sigv4Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The signer modifies the request and reads its body, which is nil for the ruler's GET requests
+	req = req.Clone(req.Context())
+	if req.Body == nil {
+		req.Body = http.NoBody
+	}
+	return t.signer.RoundTrip(req)
+}
@@ -0,0 +1,194 @@
+package cortextool
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestHeaderTransport(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+	}))
+	defer server.Close()
+
+	client := http.Client{
+		Transport: &headerTransport{
+			next: http.DefaultTransport,
+			headers: map[string]string{
+				"Authorization":    "Bearer secret",
+				"X-Scope-OrgID":    "override",
+				"X-Grafana-Org-Id": "1",
+			},
+		},
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.SetBasicAuth("user", "key")
+	req.Header.Set("X-Scope-OrgID", "tenant")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	for name, expected := range map[string]string{
+		"Authorization":    "Bearer secret",
+		"X-Scope-OrgID":    "override",
+		"X-Grafana-Org-Id": "1",
+	} {
+		if value := received.Get(name); value != expected {
+			t.Errorf("got %s header %q, expected %q", name, value, expected)
+		}
+	}
+}
+
+func TestGetHTTPHeaders(t *testing.T) {
+	providerSchema := New("dev", nil)().Schema
+
+	t.Setenv("CORTEXTOOL_HTTP_HEADERS", `{"X-Grafana-Org-Id": "2"}`)
+	d := schema.TestResourceDataRaw(t, providerSchema, map[string]any{})
+	headers, err := getHTTPHeaders(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if headers["X-Grafana-Org-Id"] != "2" {
+		t.Errorf("unexpected headers %v", headers)
+	}
+
+	// The provider's configuration takes precedence over the environment
+	d = schema.TestResourceDataRaw(t, providerSchema, map[string]any{
+		"http_headers": map[string]any{"X-Grafana-Org-Id": "1"},
+	})
+	headers, err = getHTTPHeaders(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if headers["X-Grafana-Org-Id"] != "1" {
+		t.Errorf("unexpected headers %v", headers)
+	}
+
+	t.Setenv("CORTEXTOOL_HTTP_HEADERS", `null`)
+	d = schema.TestResourceDataRaw(t, providerSchema, map[string]any{})
+	if headers, err = getHTTPHeaders(d); err != nil || len(headers) != 0 {
+		t.Errorf("got headers %v and error %v, expected no headers", headers, err)
+	}
+
+	t.Setenv("CORTEXTOOL_HTTP_HEADERS", `not json`)
+	d = schema.TestResourceDataRaw(t, providerSchema, map[string]any{})
+	if _, err := getHTTPHeaders(d); err == nil {
+		t.Error("expected an error for invalid CORTEXTOOL_HTTP_HEADERS")
+	}
+}
+
+func TestOAuth2Transport(t *testing.T) {
+	tests := []struct {
+		name            string
+		expiresIn       int
+		expectedFetches int
+	}{
+		{name: "token reused", expiresIn: 3600, expectedFetches: 1},
+		// Tokens expiring within 10 seconds are refreshed
+		{name: "token refreshed", expiresIn: 1, expectedFetches: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetches := 0
+			tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("audience") != "ruler" || r.Form.Get("scope") != "rules:write" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				if user, password, _ := r.BasicAuth(); user != "terraform" || password != "secret" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				fetches++
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "Bearer", "expires_in": %d}`, fetches, tt.expiresIn)
+			}))
+			defer tokenServer.Close()
+
+			var authorizations []string
+			rulerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorizations = append(authorizations, r.Header.Get("Authorization"))
+			}))
+			defer rulerServer.Close()
+
+			d := schema.TestResourceDataRaw(t, New("dev", nil)().Schema, map[string]any{
+				"oauth2": []any{map[string]any{
+					"token_url":     tokenServer.URL,
+					"client_id":     "terraform",
+					"client_secret": "secret",
+					"scopes":        []any{"rules:write"},
+					"audience":      "ruler",
+				}},
+			})
+			client := http.Client{
+				Transport: newOAuth2Transport(http.DefaultTransport, getOAuth2Config(d), http.DefaultTransport),
+			}
+			for i := 0; i < 2; i++ {
+				resp, err := client.Get(rulerServer.URL)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+			}
+
+			if fetches != tt.expectedFetches {
+				t.Errorf("got %d token fetches, expected %d", fetches, tt.expectedFetches)
+			}
+			expected := fmt.Sprintf("Bearer token-%d", tt.expectedFetches)
+			if authorizations[1] != expected {
+				t.Errorf("got Authorization %q, expected %q", authorizations[1], expected)
+			}
+		})
+	}
+}
+
+func TestSigV4Transport(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, New("dev", nil)().Schema, map[string]any{
+		"sigv4": []any{map[string]any{
+			"region":     "eu-west-1",
+			"access_key": "AKIDEXAMPLE",
+			"secret_key": "secret",
+		}},
+	})
+	transport, err := newSigV4Transport(http.DefaultTransport, getSigV4Config(d))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL + "/api/v1/rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	resp, err = client.Post(server.URL+"/api/v1/rules/namespace", "application/yaml", strings.NewReader("name: group"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	for _, authorization := range authorizations {
+		if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(authorization, "/eu-west-1/aps/") {
+			t.Errorf("got Authorization %q, expected a SigV4 signature", authorization)
+		}
+	}
+	if len(authorizations) != 2 {
+		t.Errorf("got %d requests, expected 2", len(authorizations))
+	}
+}
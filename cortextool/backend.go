@@ -0,0 +1,45 @@
+package cortextool
+
+import (
+	"github.com/grafana/cortex-tools/pkg/rules"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	backendLoki   = "loki"
+	backendCortex = "cortex"
+	backendMimir  = "mimir"
+
+	// defaultBackend is the backend the provider was hardcoded to before it became configurable.
+	defaultBackend = backendLoki
+)
+
+var backends = []string{backendLoki, backendCortex, backendMimir}
+
+// lintBackend returns the cortextool linting backend matching the query language of the ruler backend.
+func lintBackend(backend string) string {
+	if backend == backendLoki || backend == "" {
+		return rules.LokiBackend
+	}
+	return rules.CortexBackend
+}
+
+// resourceBackend returns the backend set on the resource, falling back to the provider's one.
+func resourceBackend(d attributeGetter, meta any) string {
+	if backend := d.Get("backend").(string); backend != "" {
+		return backend
+	}
+	return meta.(*providerData).backend
+}
+
+// setDefaultBackend plans the provider's backend for the resource unless the resource overrides it.
+func setDefaultBackend(d *schema.ResourceDiff, meta any) error {
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.GetAttr("backend").IsNull() {
+		return nil
+	}
+	if backend := meta.(*providerData).backend; d.Get("backend").(string) != backend {
+		return d.SetNew("backend", backend)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package cortextool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	cortextool "github.com/grafana/cortex-tools/pkg/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"gopkg.in/yaml.v3"
+)
+
+func dataSourceRuleNamespace() *schema.Resource {
+	return &schema.Resource{
+		Description: `
+Reads a rule namespace from the ruler without managing it.
+
+* [Official documentation](https://grafana.com/docs/loki/latest/rules/)
+* [HTTP API](https://grafana.com/docs/loki/latest/api/#ruler)
+`,
+
+		ReadContext: dataSourceRuleNamespaceRead,
+
+		Schema: map[string]*schema.Schema{
+			"namespace": {
+				Description: "The name of the namespace to read",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"tenant_id": {
+				Description: "The tenant to read the namespace of. Defaults to the provider's `tenant_id`.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"config_yaml": {
+				Description: "The namespace's groups rules definition, as normalized YAML",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"group_names": {
+				Description: "The names of the namespace's groups",
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+			},
+			"group": {
+				Description: "The namespace's groups",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "The name of the group",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"interval": {
+							Description: "How often the group's rules are evaluated, empty when the ruler's default is used",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"limit": {
+							Description: "The maximum number of alerts or series the group's rules can produce, 0 is no limit",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"rule_count": {
+							Description: "The number of rules in the group",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"rule": {
+							Description: "The group's rules",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"alert": {
+										Description: "The name of the alert, empty for recording rules",
+										Type:        schema.TypeString,
+										Computed:    true,
+									},
+									"record": {
+										Description: "The name of the recorded series, empty for alerting rules",
+										Type:        schema.TypeString,
+										Computed:    true,
+									},
+									"expr": {
+										Description: "The expression to evaluate",
+										Type:        schema.TypeString,
+										Computed:    true,
+									},
+									"for": {
+										Description: "How long the alert condition must hold before firing",
+										Type:        schema.TypeString,
+										Computed:    true,
+									},
+									"labels": {
+										Description: "The labels to add or overwrite",
+										Type:        schema.TypeMap,
+										Elem:        &schema.Schema{Type: schema.TypeString},
+										Computed:    true,
+									},
+									"annotations": {
+										Description: "The annotations to add to the alerts",
+										Type:        schema.TypeMap,
+										Elem:        &schema.Schema{Type: schema.TypeString},
+										Computed:    true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRuleNamespaceRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+	namespace := d.Get("namespace").(string)
+
+	ruleNamespace, err := getRuleNamespaceRemote(ctx, d, meta)
+	if errors.Is(err, cortextool.ErrResourceNotFound) {
+		return diag.Errorf("namespace %q not found", namespace)
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	configYaml, err := yaml.Marshal(&ruleNamespace)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(tenantResourceID(d, namespace))
+	d.Set("config_yaml", string(configYaml))
+	d.Set("group_names", ruleGroupNames(ruleNamespace.Groups))
+	groups := flattenRuleGroups(ruleNamespace.Groups)
+	for i, group := range ruleNamespace.Groups {
+		groups[i].(map[string]any)["rule_count"] = len(group.Rules)
+	}
+	if err := d.Set("group", groups); err != nil {
+		return diag.FromErr(fmt.Errorf("setting group: %w", err))
+	}
+	return diags
+}
@@ -0,0 +1,118 @@
+package cortextool
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"os"
+	"regexp"
+	"testing"
+)
+
+func TestAccDataSourceRuleNamespace(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "grafana-agent-traces-ds"
+						config_yaml = file("testdata/rules.yaml")
+					}
+
+					data "cortextool_rule_namespace" "demo" {
+						namespace = cortextool_rule_namespace.demo.namespace
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespace.demo", "id", "grafana-agent-traces-ds"),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespace.demo", "group_names.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespace.demo", "group_names.0", "grafana-agent"),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespace.demo", "group.0.rule_count", "3"),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespace.demo", "group.0.rule.1.alert", "LogWarnMessages"),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespace.demo", "group.0.rule.1.for", "3m"),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespace.demo", "group.0.rule.1.labels.team", "sre"),
+					resource.TestCheckResourceAttrPair(
+						"data.cortextool_rule_namespace.demo", "config_yaml",
+						"cortextool_rule_namespace.demo", "config_yaml"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceRuleNamespaceNotFound(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "cortextool_rule_namespace" "missing" {
+						namespace = "missing"
+					}
+					`,
+				ExpectError: regexp.MustCompile(`namespace "missing" not found`),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceRuleNamespaceTenant(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "cortextool_rule_namespace" "team_c" {
+						namespace = "grafana-agent-traces-tenant-ds"
+						tenant_id = "team-c"
+						config_yaml = file("testdata/rules.yaml")
+					}
+
+					data "cortextool_rule_namespace" "team_c" {
+						namespace = cortextool_rule_namespace.team_c.namespace
+						tenant_id = cortextool_rule_namespace.team_c.tenant_id
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespace.team_c", "id", "team-c:grafana-agent-traces-tenant-ds"),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespace.team_c", "group_names.#", "1"),
+				),
+			},
+			// The namespace does not exist for the provider's tenant
+			{
+				Config: `
+					resource "cortextool_rule_namespace" "team_c" {
+						namespace = "grafana-agent-traces-tenant-ds"
+						tenant_id = "team-c"
+						config_yaml = file("testdata/rules.yaml")
+					}
+
+					data "cortextool_rule_namespace" "default" {
+						namespace = cortextool_rule_namespace.team_c.namespace
+					}
+					`,
+				ExpectError: regexp.MustCompile(`namespace "grafana-agent-traces-tenant-ds" not found`),
+			},
+		},
+	})
+}
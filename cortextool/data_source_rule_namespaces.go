@@ -0,0 +1,133 @@
+package cortextool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	cortextool "github.com/grafana/cortex-tools/pkg/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+func dataSourceRuleNamespaces() *schema.Resource {
+	return &schema.Resource{
+		Description: `
+Lists the rule namespaces and groups configured in the ruler for the tenant.
+
+* [Official documentation](https://grafana.com/docs/loki/latest/rules/)
+* [HTTP API](https://grafana.com/docs/loki/latest/api/#ruler)
+`,
+
+		ReadContext: dataSourceRuleNamespacesRead,
+
+		Schema: map[string]*schema.Schema{
+			"tenant_id": {
+				Description: "The tenant to read the namespaces of. Defaults to the provider's `tenant_id`.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"name_regex": {
+				Description:  "Only list the namespaces whose name matches this regular expression",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"group_name_regex": {
+				Description:  "Only list the groups whose name matches this regular expression, namespaces without any matching group are left out",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"names": {
+				Description: "The names of the namespaces, sorted",
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+			},
+			"namespaces": {
+				Description: "The namespaces with their group names, sorted by namespace name. Use `{ for ns in data.cortextool_rule_namespaces.all.namespaces : ns.name => ns.group_names }` to get a map.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "The name of the namespace",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"group_names": {
+							Description: "The names of the namespace's groups",
+							Type:        schema.TypeList,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRuleNamespacesRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client, err := ruleClient(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	nameRegex := d.Get("name_regex").(string)
+	groupNameRegex := d.Get("group_name_regex").(string)
+
+	nameFilter, err := regexp.Compile(nameRegex)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	groupNameFilter, err := regexp.Compile(groupNameRegex)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// The ruler answers with a 404 when the tenant has no rules at all
+	ruleGroups, err := client.ListRules(ctx, "")
+	if err != nil && !errors.Is(err, cortextool.ErrResourceNotFound) {
+		return diag.FromErr(err)
+	}
+
+	namespaceNames := maps.Keys(ruleGroups)
+	slices.Sort(namespaceNames)
+
+	names := make([]string, 0, len(namespaceNames))
+	namespaces := make([]any, 0, len(namespaceNames))
+	for _, namespace := range namespaceNames {
+		if !nameFilter.MatchString(namespace) {
+			continue
+		}
+
+		var groupNames []string
+		for _, name := range ruleGroupNames(ruleGroups[namespace]) {
+			if groupNameFilter.MatchString(name) {
+				groupNames = append(groupNames, name)
+			}
+		}
+		if len(groupNames) == 0 {
+			continue
+		}
+
+		names = append(names, namespace)
+		namespaces = append(namespaces, map[string]any{
+			"name":        namespace,
+			"group_names": groupNames,
+		})
+	}
+
+	d.SetId(hash(nameRegex + "/" + groupNameRegex))
+	d.Set("names", names)
+	if err := d.Set("namespaces", namespaces); err != nil {
+		return diag.FromErr(fmt.Errorf("setting namespaces: %w", err))
+	}
+	return diags
+}
@@ -0,0 +1,104 @@
+package cortextool
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"os"
+	"testing"
+)
+
+func TestAccDataSourceRuleNamespaces(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "cortextool_rule_namespace" "first" {
+						namespace = "inventory-first"
+						config_yaml = file("testdata/rules.yaml")
+					}
+
+					resource "cortextool_rule_namespace" "second" {
+						namespace = "inventory-second"
+						config_yaml = file("testdata/rules2.yaml")
+					}
+
+					data "cortextool_rule_namespaces" "inventory" {
+						name_regex = "^inventory-"
+						depends_on = [cortextool_rule_namespace.first, cortextool_rule_namespace.second]
+					}
+
+					data "cortextool_rule_namespaces" "second" {
+						name_regex = "^inventory-s"
+						group_name_regex = "^grafana-"
+						depends_on = [cortextool_rule_namespace.first, cortextool_rule_namespace.second]
+					}
+
+					data "cortextool_rule_namespaces" "none" {
+						name_regex = "^inventory-"
+						group_name_regex = "^unknown$"
+						depends_on = [cortextool_rule_namespace.first, cortextool_rule_namespace.second]
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespaces.inventory", "names.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespaces.inventory", "names.0", "inventory-first"),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespaces.inventory", "namespaces.1.name", "inventory-second"),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespaces.inventory", "namespaces.1.group_names.0", "grafana-agent"),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespaces.second", "names.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespaces.second", "names.0", "inventory-second"),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespaces.none", "names.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceRuleNamespacesTenant(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "cortextool_rule_namespace" "team_e" {
+						namespace = "inventory-tenant"
+						tenant_id = "team-e"
+						config_yaml = file("testdata/rules.yaml")
+					}
+
+					data "cortextool_rule_namespaces" "team_e" {
+						tenant_id = "team-e"
+						depends_on = [cortextool_rule_namespace.team_e]
+					}
+
+					data "cortextool_rule_namespaces" "default" {
+						name_regex = "^inventory-tenant$"
+						depends_on = [cortextool_rule_namespace.team_e]
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespaces.team_e", "names.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespaces.team_e", "names.0", "inventory-tenant"),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespaces.default", "names.#", "0"),
+				),
+			},
+		},
+	})
+}
@@ -2,34 +2,83 @@ package cortextool
 
 import (
 	"context"
-	"errors"
+	"sync"
+
+	cortextool "github.com/grafana/cortex-tools/pkg/client"
 	"github.com/grafana/cortex-tools/pkg/rules"
 	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
 )
 
 type MockCortexRuleClient struct {
-	namespaces map[string]rules.RuleNamespace
+	// mu guards namespaces as groups may be written concurrently
+	mu           *sync.Mutex
+	namespaces   map[string]rules.RuleNamespace
+	alertmanager *mockAlertmanagerConfig
+	backend      string
+	// groupErrors holds the errors returned when creating the groups with the given names
+	groupErrors map[string]error
+	// groupWrites counts the successful creations of the groups with the given names
+	groupWrites map[string]int
+	// tenants holds the clients of the other tenants, keyed by tenant
+	tenants map[string]MockCortexRuleClient
+}
+
+type mockAlertmanagerConfig struct {
+	config    string
+	templates map[string]string
 }
 
-func NewMockCortexRuleClient() MockCortexRuleClient {
+func NewMockCortexRuleClient(backend string) MockCortexRuleClient {
 	return MockCortexRuleClient{
-		namespaces: map[string]rules.RuleNamespace{},
+		mu:           &sync.Mutex{},
+		namespaces:   map[string]rules.RuleNamespace{},
+		alertmanager: &mockAlertmanagerConfig{},
+		backend:      backend,
+		groupErrors:  map[string]error{},
+		groupWrites:  map[string]int{},
+		tenants:      map[string]MockCortexRuleClient{},
+	}
+}
+
+func (m MockCortexRuleClient) ForTenant(tenantID string) CortexRuleClient {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tenant, ok := m.tenants[tenantID]
+	if !ok {
+		tenant = NewMockCortexRuleClient(m.backend)
+		m.tenants[tenantID] = tenant
 	}
+	return tenant
 }
 
-func (m MockCortexRuleClient) CreateRuleGroup(ctx context.Context, namespace string, group rwrulefmt.RuleGroup) error {
-	m.DeleteRuleGroup(ctx, namespace, group.Name)
+func (m MockCortexRuleClient) CreateRuleGroup(_ context.Context, namespace string, group rwrulefmt.RuleGroup) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.groupErrors[group.Name]; err != nil {
+		return err
+	}
+	m.deleteRuleGroup(namespace, group.Name)
 	if ns, ok := m.namespaces[namespace]; ok {
 		ns.Groups = append(ns.Groups, group)
 		m.namespaces[namespace] = ns
 	} else {
 		m.namespaces[namespace] = rules.RuleNamespace{Groups: []rwrulefmt.RuleGroup{group}}
 	}
-	m.namespaces[namespace].LintExpressions(rules.LokiBackend)
+	m.namespaces[namespace].LintExpressions(lintBackend(m.backend))
+	m.groupWrites[group.Name]++
 	return nil
 }
 
 func (m MockCortexRuleClient) DeleteRuleGroup(_ context.Context, namespace string, groupName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.deleteRuleGroup(namespace, groupName)
+}
+
+func (m MockCortexRuleClient) deleteRuleGroup(namespace string, groupName string) error {
 	foundGroup := false
 	if ns, nsOk := m.namespaces[namespace]; nsOk {
 		newGroups := make([]rwrulefmt.RuleGroup, 0, len(ns.Groups))
@@ -48,14 +97,49 @@ func (m MockCortexRuleClient) DeleteRuleGroup(_ context.Context, namespace strin
 		}
 	}
 
-	return errors.New("requested resource not found")
+	return cortextool.ErrResourceNotFound
 }
 
 func (m MockCortexRuleClient) ListRules(_ context.Context, namespace string) (map[string][]rwrulefmt.RuleGroup, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if namespace == "" {
+		ruleGroups := map[string][]rwrulefmt.RuleGroup{}
+		for name, ns := range m.namespaces {
+			if len(ns.Groups) > 0 {
+				ruleGroups[name] = ns.Groups
+			}
+		}
+		if len(ruleGroups) == 0 {
+			return nil, cortextool.ErrResourceNotFound
+		}
+		return ruleGroups, nil
+	}
+
 	ns, ok := m.namespaces[namespace]
 	if ok {
 		return map[string][]rwrulefmt.RuleGroup{namespace: ns.Groups}, nil
 	}
 
-	return nil, errors.New("requested resource not found")
+	return nil, cortextool.ErrResourceNotFound
+}
+
+func (m MockCortexRuleClient) CreateAlertmanagerConfig(_ context.Context, config string, templates map[string]string) error {
+	m.alertmanager.config = config
+	m.alertmanager.templates = templates
+	return nil
+}
+
+func (m MockCortexRuleClient) DeleteAlermanagerConfig(_ context.Context) error {
+	m.alertmanager.config = ""
+	m.alertmanager.templates = nil
+	return nil
+}
+
+func (m MockCortexRuleClient) GetAlertmanagerConfig(_ context.Context) (string, map[string]string, error) {
+	if m.alertmanager.config == "" {
+		return "", nil, cortextool.ErrResourceNotFound
+	}
+	return m.alertmanager.config, m.alertmanager.templates, nil
 }
@@ -0,0 +1,51 @@
+package cortextool
+
+import "sync"
+
+// forEachParallel calls fn for the indexes 0 to n-1 with at most parallelism concurrent calls, and
+// returns the errors of the failed calls in index order. When stopOnError is set, the calls which
+// have not started yet are skipped once a call failed.
+func forEachParallel(parallelism, n int, stopOnError bool, fn func(i int) error) []error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed bool
+	)
+	results := make([]error, n)
+	sem := make(chan struct{}, parallelism)
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		mu.Lock()
+		skip := stopOnError && failed
+		mu.Unlock()
+		if skip {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(i); err != nil {
+				mu.Lock()
+				results[i] = err
+				failed = true
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, err := range results {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,152 @@
+package cortextool
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/grafana/cortex-tools/pkg/rules"
+	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"golang.org/x/exp/slices"
+)
+
+func policySchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"required_labels": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Labels every alerting rule must set.",
+			},
+			"required_annotations": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Annotations every alerting rule must set.",
+			},
+			"allowed_severities": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Values allowed for the `severity` label, any value is allowed when empty.",
+			},
+			"label_patterns": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Regular expressions the values of the labels must fully match, by label name.",
+			},
+			"forbidden_expr_patterns": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.StringIsValidRegExp},
+				Description: "Regular expressions the rules expressions must not match, for instance `=~\"\\.\\*\"` to forbid the unbounded selectors such as `{job=~\".*\"}`.",
+			},
+		},
+	}
+}
+
+// rulePolicy holds the constraints the rules must comply with, whatever the resource.
+type rulePolicy struct {
+	requiredLabels        []string
+	requiredAnnotations   []string
+	allowedSeverities     []string
+	labelPatterns         map[string]*regexp.Regexp
+	forbiddenExprPatterns []*regexp.Regexp
+}
+
+// getRulePolicy returns the provider's policy, or nil when it does not set one.
+func getRulePolicy(d *schema.ResourceData) (*rulePolicy, error) {
+	blocks := d.Get("policy").([]any)
+	if len(blocks) == 0 || blocks[0] == nil {
+		return nil, nil
+	}
+	block := blocks[0].(map[string]any)
+
+	policy := &rulePolicy{
+		requiredLabels:      sortedStrings(block["required_labels"].(*schema.Set)),
+		requiredAnnotations: sortedStrings(block["required_annotations"].(*schema.Set)),
+		allowedSeverities:   sortedStrings(block["allowed_severities"].(*schema.Set)),
+		labelPatterns:       map[string]*regexp.Regexp{},
+	}
+	for name, pattern := range stringValueMap(block["label_patterns"].(map[string]any)) {
+		// Anchored as the label matchers are
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("policy: label_patterns: %q: %w", name, err)
+		}
+		policy.labelPatterns[name] = re
+	}
+	for _, pattern := range block["forbidden_expr_patterns"].([]any) {
+		re, err := regexp.Compile(pattern.(string))
+		if err != nil {
+			return nil, fmt.Errorf("policy: forbidden_expr_patterns: %w", err)
+		}
+		policy.forbiddenExprPatterns = append(policy.forbiddenExprPatterns, re)
+	}
+	return policy, nil
+}
+
+func sortedStrings(set *schema.Set) []string {
+	values := make([]string, 0, set.Len())
+	for _, value := range set.List() {
+		values = append(values, value.(string))
+	}
+	slices.Sort(values)
+	return values
+}
+
+// checkRuleNamespace returns the rules of the namespace breaking the policy.
+func (p *rulePolicy) checkRuleNamespace(ruleNamespace rules.RuleNamespace) []ruleIssue {
+	var issues []ruleIssue
+	for _, group := range ruleNamespace.Groups {
+		issues = append(issues, p.checkRuleGroup(ruleNamespace.Namespace, group)...)
+	}
+	return issues
+}
+
+// checkRuleGroup returns the rules of the group breaking the policy, none when there is no policy.
+func (p *rulePolicy) checkRuleGroup(namespace string, group rwrulefmt.RuleGroup) []ruleIssue {
+	if p == nil {
+		return nil
+	}
+	var issues []ruleIssue
+	for i, rule := range group.Rules {
+		var errs []error
+		if rule.Alert.Value != "" {
+			for _, name := range p.requiredLabels {
+				if _, ok := rule.Labels[name]; !ok {
+					errs = append(errs, fmt.Errorf("labels: %q is required by the policy", name))
+				}
+			}
+			for _, name := range p.requiredAnnotations {
+				if _, ok := rule.Annotations[name]; !ok {
+					errs = append(errs, fmt.Errorf("annotations: %q is required by the policy", name))
+				}
+			}
+		}
+		if severity, ok := rule.Labels["severity"]; ok && len(p.allowedSeverities) > 0 &&
+			!slices.Contains(p.allowedSeverities, severity) {
+			errs = append(errs, fmt.Errorf("labels: severity %q is not allowed by the policy, use one of %q",
+				severity, p.allowedSeverities))
+		}
+		for _, name := range sortedKeys(p.labelPatterns) {
+			if value, ok := rule.Labels[name]; ok && !p.labelPatterns[name].MatchString(value) {
+				errs = append(errs, fmt.Errorf("labels: %s %q does not match the policy's pattern %q",
+					name, value, p.labelPatterns[name]))
+			}
+		}
+		for _, re := range p.forbiddenExprPatterns {
+			if re.MatchString(rule.Expr.Value) {
+				errs = append(errs, fmt.Errorf("expr: matches the pattern %q forbidden by the policy", re))
+			}
+		}
+
+		for _, err := range errs {
+			issues = append(issues, newRuleIssue(namespace, group, i, err))
+		}
+	}
+	return issues
+}
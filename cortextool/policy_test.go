@@ -0,0 +1,60 @@
+package cortextool
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/exp/slices"
+)
+
+func TestRulePolicy(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, New("dev", nil)().Schema, map[string]any{
+		"policy": []any{map[string]any{
+			"required_labels":         []any{"team", "severity"},
+			"required_annotations":    []any{"runbook_url"},
+			"allowed_severities":      []any{"critical", "warning"},
+			"label_patterns":          map[string]any{"team": "[a-z]+"},
+			"forbidden_expr_patterns": []any{`=~"\.\*"`},
+		}},
+	})
+	policy, err := getRulePolicy(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compliant := testAlertRule("Compliant", `up{job="node"} == 0`)
+	compliant.Labels = map[string]string{"team": "sre", "severity": "critical"}
+	compliant.Annotations = map[string]string{"runbook_url": "https://example.com/runbooks/node-down"}
+	breaking := testAlertRule("Breaking", `up{job=~".*"} == 0`)
+	breaking.Labels = map[string]string{"team": "SRE-1", "severity": "page"}
+	// Recording rules have no annotations and do not require labels
+	record := testRecordRule("job:up:sum", `sum by (job) (up)`)
+
+	var got []string
+	for _, issue := range policy.checkRuleNamespace(testRuleNamespace(compliant, breaking, record)) {
+		got = append(got, issue.Error())
+	}
+	expected := []string{
+		`namespace "ns", group "group", rule "Breaking": annotations: "runbook_url" is required by the policy`,
+		`namespace "ns", group "group", rule "Breaking": labels: severity "page" is not allowed by the policy, use one of ["critical" "warning"]`,
+		`namespace "ns", group "group", rule "Breaking": labels: team "SRE-1" does not match the policy's pattern "^(?:[a-z]+)$"`,
+		`namespace "ns", group "group", rule "Breaking": expr: matches the pattern "=~\"\\.\\*\"" forbidden by the policy`,
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestRulePolicyNone(t *testing.T) {
+	policy, err := getRulePolicy(schema.TestResourceDataRaw(t, New("dev", nil)().Schema, map[string]any{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy != nil {
+		t.Fatalf("got %+v, expected no policy", policy)
+	}
+	if issues := policy.checkRuleNamespace(testRuleNamespace(testAlertRule("Down", `up == 0`))); len(issues) != 0 {
+		t.Errorf("got %v, expected no issues without a policy", issues)
+	}
+}
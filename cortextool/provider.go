@@ -2,13 +2,18 @@ package cortextool
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	cortextool "github.com/grafana/cortex-tools/pkg/client"
-	"github.com/grafana/dskit/crypto/tls"
+	"github.com/prometheus/common/model"
 )
 
 func init() {
@@ -17,8 +22,6 @@ func init() {
 	schema.DescriptionKind = schema.StringMarkdown
 }
 
-var storeRulesSha256 bool
-
 // New returns a newly created provider
 func New(version string, cortexClient *CortexRuleClient) func() *schema.Provider {
 	return func() *schema.Provider {
@@ -34,11 +37,18 @@ func New(version string, cortexClient *CortexRuleClient) func() *schema.Provider
 					Description:  "Address to use when contacting Grafana Loki. May alternatively be set via the `CORTEXTOOL_ADDRESS` environment variable.",
 					ValidateFunc: validation.IsURLWithHTTPorHTTPS,
 				},
+				"backend": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CORTEXTOOL_BACKEND", defaultBackend),
+					Description:  "Ruler backend to manage rules for, one of `loki`, `cortex` or `mimir`. It selects the query language used to lint and validate rules, and the ruler API routes. Defaults to `loki`. May alternatively be set via the `CORTEXTOOL_BACKEND` environment variable.",
+					ValidateFunc: validation.StringInSlice(backends, false),
+				},
 				"tenant_id": {
 					Type:        schema.TypeString,
 					Optional:    true,
 					DefaultFunc: schema.EnvDefaultFunc("CORTEXTOOL_TENANT_ID", nil),
-					Description: "Tenant ID to use when contacting Grafana Loki. May alternatively be set via the `CORTEXTOOL_TENANT_ID` environment variable.",
+					Description: "Tenant ID to use when contacting Grafana Loki. Resources and data sources may override it with `tenant_id`. May alternatively be set via the `CORTEXTOOL_TENANT_ID` environment variable.",
 				},
 				"api_user": {
 					Type:        schema.TypeString,
@@ -53,23 +63,107 @@ func New(version string, cortexClient *CortexRuleClient) func() *schema.Provider
 					DefaultFunc: schema.EnvDefaultFunc("CORTEXTOOL_API_KEY", nil),
 					Description: "API key to use when contacting Grafana Loki. May alternatively be set via the `CORTEXTOOL_API_KEY` environment variable.",
 				},
-				"tls_key_path": {
-					Type:        schema.TypeString,
+				"auth_token": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					Sensitive:     true,
+					DefaultFunc:   schema.EnvDefaultFunc("CORTEXTOOL_AUTH_TOKEN", nil),
+					Description:   "Bearer token to use when contacting Grafana Loki, instead of `api_user` and `api_key`. May alternatively be set via the `CORTEXTOOL_AUTH_TOKEN` environment variable.",
+					ConflictsWith: []string{"api_user", "api_key"},
+				},
+				"oauth2": {
+					Type:          schema.TypeList,
+					Optional:      true,
+					MaxItems:      1,
+					Elem:          oauth2Schema(),
+					Description:   "OAuth2 client credentials to fetch the bearer tokens to use when contacting Grafana Loki, instead of `api_user` and `api_key`.",
+					ConflictsWith: []string{"api_user", "api_key", "auth_token", "sigv4"},
+				},
+				"sigv4": {
+					Type:          schema.TypeList,
+					Optional:      true,
+					MaxItems:      1,
+					Elem:          sigv4Schema(),
+					Description:   "AWS SigV4 signing of the requests, to manage the rules of Amazon Managed Service for Prometheus. Use the `cortex` backend and the workspace's URL as `address`, for instance `https://aps-workspaces.eu-west-1.amazonaws.com/workspaces/ws-example`.",
+					ConflictsWith: []string{"api_user", "api_key", "auth_token", "oauth2"},
+				},
+				"http_headers": {
+					Type:        schema.TypeMap,
 					Optional:    true,
-					DefaultFunc: schema.EnvDefaultFunc("CORTEXTOOL_TLS_KEY_PATH", nil),
-					Description: "Client TLS key file to use to authenticate to the Loki server. May alternatively be set via the `CORTEXTOOL_TLS_KEY_PATH` environment variable.",
+					Sensitive:   true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "Extra HTTP headers to send with every request, they override the ones set by the provider such as `X-Scope-OrgID`. May alternatively be set via the `CORTEXTOOL_HTTP_HEADERS` environment variable as a JSON object.",
+				},
+				"tls_key_path": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					DefaultFunc:   schema.EnvDefaultFunc("CORTEXTOOL_TLS_KEY_PATH", nil),
+					Description:   "Client TLS key file to use to authenticate to the Loki server. May alternatively be set via the `CORTEXTOOL_TLS_KEY_PATH` environment variable.",
+					ConflictsWith: []string{"tls_key_pem"},
+				},
+				"tls_key_pem": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					Sensitive:     true,
+					DefaultFunc:   schema.EnvDefaultFunc("CORTEXTOOL_TLS_KEY_PEM", nil),
+					Description:   "Client TLS key, in PEM format, to use to authenticate to the Loki server, instead of `tls_key_path`. May alternatively be set via the `CORTEXTOOL_TLS_KEY_PEM` environment variable.",
+					ConflictsWith: []string{"tls_key_path"},
 				},
 				"tls_cert_path": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					DefaultFunc:   schema.EnvDefaultFunc("CORTEXTOOL_TLS_CERT_PATH", nil),
+					Description:   "Client TLS certificate file to use to authenticate to the Loki server. May alternatively be set via the `CORTEXTOOL_TLS_CERT_PATH` environment variable.",
+					ConflictsWith: []string{"tls_cert_pem"},
+				},
+				"tls_cert_pem": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					Sensitive:     true,
+					DefaultFunc:   schema.EnvDefaultFunc("CORTEXTOOL_TLS_CERT_PEM", nil),
+					Description:   "Client TLS certificate, in PEM format, to use to authenticate to the Loki server, instead of `tls_cert_path`. May alternatively be set via the `CORTEXTOOL_TLS_CERT_PEM` environment variable.",
+					ConflictsWith: []string{"tls_cert_path"},
+				},
+				"tls_ca_path": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					DefaultFunc:   schema.EnvDefaultFunc("CORTEXTOOL_TLS_CA_PATH", nil),
+					Description:   "Certificate CA bundle to use to verify the Loki server's certificate. May alternatively be set via the `CORTEXTOOL_TLS_CA_PATH` environment variable.",
+					ConflictsWith: []string{"tls_ca_pem"},
+				},
+				"tls_ca_pem": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					Sensitive:     true,
+					DefaultFunc:   schema.EnvDefaultFunc("CORTEXTOOL_TLS_CA_PEM", nil),
+					Description:   "Certificate CA bundle, in PEM format, to use to verify the Loki server's certificate, instead of `tls_ca_path`. May alternatively be set via the `CORTEXTOOL_TLS_CA_PEM` environment variable.",
+					ConflictsWith: []string{"tls_ca_path"},
+				},
+				"tls_server_name": {
 					Type:        schema.TypeString,
 					Optional:    true,
-					DefaultFunc: schema.EnvDefaultFunc("CORTEXTOOL_TLS_CERT_PATH", nil),
-					Description: "Client TLS certificate file to use to authenticate to the Loki server. May alternatively be set via the `CORTEXTOOL_TLS_CERT_PATH` environment variable.",
+					DefaultFunc: schema.EnvDefaultFunc("CORTEXTOOL_TLS_SERVER_NAME", nil),
+					Description: "Name to verify the Loki server's certificate against, instead of the host of `address`. May alternatively be set via the `CORTEXTOOL_TLS_SERVER_NAME` environment variable.",
 				},
-				"tls_ca_path": {
+				"proxy_url": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CORTEXTOOL_PROXY_URL", nil),
+					Description:  "URL of the proxy to send the requests through, instead of the one set by the `HTTP_PROXY` and `HTTPS_PROXY` environment variables. May alternatively be set via the `CORTEXTOOL_PROXY_URL` environment variable.",
+					ValidateFunc: validation.IsURLWithScheme([]string{"http", "https", "socks5"}),
+				},
+				"no_proxy": {
 					Type:        schema.TypeString,
 					Optional:    true,
-					DefaultFunc: schema.EnvDefaultFunc("CORTEXTOOL_TLS_CA_PATH", nil),
-					Description: "Certificate CA bundle to use to verify the Loki server's certificate. May alternatively be set via the `CORTEXTOOL_TLS_CA_PATH` environment variable.",
+					DefaultFunc: schema.EnvDefaultFunc("CORTEXTOOL_NO_PROXY", nil),
+					Description: "Comma-separated list of the hosts, domains and CIDRs to reach without the proxy, instead of the `NO_PROXY` environment variable. May alternatively be set via the `CORTEXTOOL_NO_PROXY` environment variable.",
+				},
+				"max_idle_conns": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CORTEXTOOL_MAX_IDLE_CONNS", 0),
+					Description:  "Maximum number of idle connections kept open to the ruler, `0` keeps the default of the HTTP client. May alternatively be set via the `CORTEXTOOL_MAX_IDLE_CONNS` environment variable.",
+					ValidateFunc: validation.IntAtLeast(0),
 				},
 				"insecure_skip_verify": {
 					Type:        schema.TypeBool,
@@ -81,12 +175,90 @@ func New(version string, cortexClient *CortexRuleClient) func() *schema.Provider
 					Type:        schema.TypeBool,
 					Optional:    true,
 					DefaultFunc: schema.EnvDefaultFunc("CORTEXTOOL_STORE_RULES_SHA256", false),
-					Description: "Set to true if you want to save only the sha256sum instead of namespace's groups rules definition in the tfstate. May alternatively be set via the `CORTEXTOOL_STORE_RULES_SHA256` environment variable.",
+					Description: "Set to true if you want to save only the sha256sum instead of namespace's groups rules definition in the tfstate. Resources may override it with `state_format`. May alternatively be set via the `CORTEXTOOL_STORE_RULES_SHA256` environment variable.",
+				},
+				"rule_conventions_severity": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CORTEXTOOL_RULE_CONVENTIONS_SEVERITY", ruleSeverityWarning),
+					Description:  "How the recording rule names not following the `level:metric:operations` convention are reported, one of `warning` or `error`. Warnings are raised when applying the rules, while `error` fails the plan. Defaults to `warning`. May alternatively be set via the `CORTEXTOOL_RULE_CONVENTIONS_SEVERITY` environment variable.",
+					ValidateFunc: validation.StringInSlice(ruleSeverities, false),
+				},
+				"policy": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					MaxItems:    1,
+					Elem:        policySchema(),
+					Description: "Policy the rules of every namespace and group must comply with, checked when planning and before writing the groups to the ruler.",
+				},
+				"max_parallel_requests": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CORTEXTOOL_MAX_PARALLEL_REQUESTS", 1),
+					Description:  "Maximum number of concurrent requests sent to the ruler when creating, updating or deleting the groups of a namespace. Defaults to `1`. May alternatively be set via the `CORTEXTOOL_MAX_PARALLEL_REQUESTS` environment variable.",
+					ValidateFunc: validation.IntAtLeast(1),
+				},
+				"use_legacy_routes": {
+					Type:          schema.TypeBool,
+					Optional:      true,
+					DefaultFunc:   schema.EnvDefaultFunc("CORTEXTOOL_USE_LEGACY_ROUTES", nil),
+					Description:   "Use the legacy `/api/prom/rules` ruler API routes. Defaults to `true` for the `loki` backend when `ruler_api_path` is not set, as older Loki rulers only serve them, and to `false` otherwise. May alternatively be set via the `CORTEXTOOL_USE_LEGACY_ROUTES` environment variable.",
+					ConflictsWith: []string{"ruler_api_path"},
+				},
+				"ruler_api_path": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("CORTEXTOOL_RULER_API_PATH", nil),
+					Description: "Route of the ruler API, or `auto` to probe the routes the ruler answers. Defaults to the legacy routes for the `loki` backend, see `use_legacy_routes`, `/api/v1/rules` for `cortex` and `/prometheus/config/v1/rules` for `mimir`. May alternatively be set via the `CORTEXTOOL_RULER_API_PATH` environment variable.",
+					ValidateFunc: validation.Any(
+						validation.StringInSlice([]string{rulerAPIPathAuto}, false),
+						validation.StringMatch(regexp.MustCompile(`^/`), "must start with /"),
+					),
+					ConflictsWith: []string{"use_legacy_routes"},
+				},
+				"request_timeout": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CORTEXTOOL_REQUEST_TIMEOUT", "1m"),
+					Description:  "Timeout of each request to the ruler and the Alertmanager, `0s` disables it. Defaults to `1m`. May alternatively be set via the `CORTEXTOOL_REQUEST_TIMEOUT` environment variable.",
+					ValidateFunc: validateDuration,
+				},
+				"retry_max_attempts": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CORTEXTOOL_RETRY_MAX_ATTEMPTS", 3),
+					Description:  "Maximum number of attempts of a ruler or Alertmanager request failing with a transient error, `1` disables retries. Defaults to `3`. May alternatively be set via the `CORTEXTOOL_RETRY_MAX_ATTEMPTS` environment variable.",
+					ValidateFunc: validation.IntAtLeast(1),
+				},
+				"retry_backoff_base": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CORTEXTOOL_RETRY_BACKOFF_BASE", "1s"),
+					Description:  "Delay before the first retry, doubled at each following retry. A longer `Retry-After` sent by the server takes precedence. Defaults to `1s`. May alternatively be set via the `CORTEXTOOL_RETRY_BACKOFF_BASE` environment variable.",
+					ValidateFunc: validateDuration,
+				},
+				"retry_backoff_max": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CORTEXTOOL_RETRY_BACKOFF_MAX", "30s"),
+					Description:  "Maximum delay between two retries. Defaults to `30s`. May alternatively be set via the `CORTEXTOOL_RETRY_BACKOFF_MAX` environment variable.",
+					ValidateFunc: validateDuration,
+				},
+				"retry_status_codes": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeInt, ValidateFunc: validation.IntBetween(400, 599)},
+					Description: "HTTP status codes of the ruler and Alertmanager responses to retry. Defaults to `429`, `500`, `502`, `503` and `504`.",
 				},
 			},
-			DataSourcesMap: map[string]*schema.Resource{},
+			DataSourcesMap: map[string]*schema.Resource{
+				"cortextool_rule_namespace":  dataSourceRuleNamespace(),
+				"cortextool_rule_namespaces": dataSourceRuleNamespaces(),
+			},
 			ResourcesMap: map[string]*schema.Resource{
-				"cortextool_rule_namespace": resourceRuleNamespace(),
+				"cortextool_rule_namespace":      resourceRuleNamespace(),
+				"cortextool_rule_group":          resourceRuleGroup(),
+				"cortextool_alertmanager_config": resourceAlertmanagerConfig(),
 			},
 		}
 
@@ -103,35 +275,151 @@ func configure(version string, p *schema.Provider, cortexClient *CortexRuleClien
 		)
 		p.UserAgent("terraform-provider-cortextool", version)
 
-		c := &providerData{}
-		if cortexClient != nil {
-			c.cli = cortexClient
-		} else {
-			cc, err := getDefaultCortexClient(d)
-			if err != nil {
-				return nil, diag.FromErr(err)
-			}
-			c.cli = &cc
+		c := &providerData{
+			backend:                 d.Get("backend").(string),
+			tenantID:                d.Get("tenant_id").(string),
+			stateFormat:             providerStateFormat(d.Get("store_rules_sha256").(bool)),
+			maxParallelRequests:     d.Get("max_parallel_requests").(int),
+			ruleConventionsSeverity: d.Get("rule_conventions_severity").(string),
 		}
+		policy, err := getRulePolicy(d)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		c.policy = policy
+		retries, err := getRetryConfig(d)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		// Each attempt is bounded by the request timeout
+		requestTimeout, err := model.ParseDuration(d.Get("request_timeout").(string))
+		if err != nil {
+			return nil, diag.FromErr(fmt.Errorf("request_timeout: %w", err))
+		}
+
+		c.newTenantClient = func(ctx context.Context, tenantID string) (*tenantClient, error) {
+			var cli CortexRuleClient
+			switch {
+			case cortexClient == nil:
+				cc, err := getDefaultCortexClient(ctx, d, tenantID, retries)
+				if err != nil {
+					return nil, err
+				}
+				cli = cc
+			case tenantID == c.tenantID:
+				cli = *cortexClient
+			default:
+				mtc, ok := (*cortexClient).(multiTenantClient)
+				if !ok {
+					return nil, errors.New("the client only serves the provider's tenant")
+				}
+				cli = mtc.ForTenant(tenantID)
+			}
 
-		storeRulesSha256 = d.Get("store_rules_sha256").(bool)
+			tc := &tenantClient{}
+			// The cortextool client serves both the ruler and the Alertmanager APIs
+			if amc, ok := cli.(CortexAlertmanagerClient); ok {
+				if requestTimeout > 0 {
+					amc = newTimeoutAlertmanagerClient(amc, time.Duration(requestTimeout))
+				}
+				if retries.maxAttempts > 1 {
+					amc = newRetryingAlertmanagerClient(amc, retries)
+				}
+				tc.amCli = amc
+			}
+			if requestTimeout > 0 {
+				cli = newTimeoutRuleClient(cli, time.Duration(requestTimeout))
+			}
+			if retries.maxAttempts > 1 {
+				cli = newRetryingRuleClient(cli, retries)
+			}
+			tc.cli = cli
+			return tc, nil
+		}
+		// The clients of the other tenants are created when a resource first needs them
+		tc, err := c.newTenantClient(ctx, c.tenantID)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		c.tenantClients = map[string]*tenantClient{c.tenantID: tc}
 
 		return c, diags
 	}
 }
 
-func getDefaultCortexClient(d *schema.ResourceData) (CortexRuleClient, error) {
-	return cortextool.New(cortextool.Config{
-		User:    d.Get("api_user").(string),
-		Key:     d.Get("api_key").(string),
-		Address: d.Get("address").(string),
-		ID:      d.Get("tenant_id").(string),
-		TLS: tls.ClientConfig{
-			CAPath:             d.Get("tls_ca_path").(string),
-			CertPath:           d.Get("tls_cert_path").(string),
-			KeyPath:            d.Get("tls_key_path").(string),
-			InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
-		},
-		UseLegacyRoutes: true,
+func getDefaultCortexClient(ctx context.Context, d *schema.ResourceData, tenantID string, retries retryConfig) (CortexRuleClient, error) {
+	address := d.Get("address").(string)
+	backend := d.Get("backend").(string)
+	legacyRoutes := useLegacyRoutes(d)
+
+	client, err := cortextool.New(cortextool.Config{
+		User:            d.Get("api_user").(string),
+		Key:             d.Get("api_key").(string),
+		AuthToken:       d.Get("auth_token").(string),
+		Address:         address,
+		ID:              tenantID,
+		TLS:             getTLSConfig(d),
+		UseLegacyRoutes: legacyRoutes,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	baseTransport := newBaseTransport(d, client.Client.Transport)
+	transport := baseTransport
+	// The requests are signed once all the other transports modified them
+	if sigv4Config := getSigV4Config(d); sigv4Config != nil {
+		if transport, err = newSigV4Transport(transport, sigv4Config); err != nil {
+			return nil, err
+		}
+	}
+	headers, err := getHTTPHeaders(d)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) > 0 {
+		transport = &headerTransport{
+			next:    transport,
+			headers: headers,
+		}
+	}
+	// The extra headers take precedence over the OAuth2 token
+	if oauth2Config := getOAuth2Config(d); oauth2Config != nil {
+		transport = newOAuth2Transport(transport, oauth2Config, baseTransport)
+	}
+	// Surface the status and Retry-After of the responses to retry
+	if retries.maxAttempts > 1 {
+		transport = &statusErrorTransport{
+			next:        transport,
+			statusCodes: retries.statusCodes,
+		}
+	}
+
+	if !legacyRoutes {
+		apiPath := d.Get("ruler_api_path").(string)
+		if apiPath == "" {
+			apiPath = defaultRulerAPIPath(backend)
+		}
+		if apiPath == rulerAPIPathAuto {
+			probe := rulerProbe{
+				client:   &http.Client{Transport: transport},
+				address:  address,
+				user:     d.Get("api_user").(string),
+				key:      d.Get("api_key").(string),
+				tenantID: tenantID,
+			}
+			if apiPath, err = probe.detectRulerAPIPath(ctx, backend); err != nil {
+				return nil, err
+			}
+		}
+		// The cortextool client only knows about the Cortex and legacy routes
+		if apiPath != rulerAPIPathCortex {
+			if transport, err = newRulerPathTransport(transport, address, apiPath); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	client.Client.Transport = transport
+	return client, nil
 }
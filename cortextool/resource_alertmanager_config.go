@@ -0,0 +1,181 @@
+package cortextool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	cortextool "github.com/grafana/cortex-tools/pkg/client"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/prometheus/alertmanager/config"
+	"gopkg.in/yaml.v3"
+)
+
+func resourceAlertmanagerConfig() *schema.Resource {
+	return &schema.Resource{
+		Description: `
+* [Official documentation](https://cortexmetrics.io/docs/architecture/#alertmanager)
+* [HTTP API](https://cortexmetrics.io/docs/api/#set-alertmanager-configuration)
+`,
+
+		CreateContext: createAlertmanagerConfig,
+		ReadContext:   readAlertmanagerConfig,
+		UpdateContext: createAlertmanagerConfig,
+		DeleteContext: deleteAlertmanagerConfig,
+		Importer: &schema.ResourceImporter{
+			StateContext: importAlertmanagerConfig,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"tenant_id": {
+				Description: "The tenant the configuration belongs to. Defaults to the provider's `tenant_id`.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"config_yaml": {
+				Description:      "The tenant's Alertmanager configuration",
+				Type:             schema.TypeString,
+				ValidateDiagFunc: validateAlertmanagerYaml,
+				DiffSuppressFunc: diffAlertmanagerConfig,
+				Required:         true,
+			},
+			"template_files": {
+				Description: "The Alertmanager notification templates, keyed by file name",
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+			},
+		},
+	}
+}
+
+func formatAlertmanagerConfig(configYaml string) (string, error) {
+	var alertmanagerConfig map[string]any
+	err := yaml.Unmarshal([]byte(configYaml), &alertmanagerConfig)
+	if err != nil {
+		return "", err
+	}
+	newYamlBytes, err := yaml.Marshal(alertmanagerConfig)
+	return string(newYamlBytes), err
+}
+
+func validateAlertmanagerYaml(value any, k cty.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	configYaml := value.(string)
+	_, err := config.Load(configYaml)
+	if err != nil {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Alertmanager configuration is not valid.",
+				Detail:        err.Error(),
+				AttributePath: k,
+			},
+		}
+	}
+	return diags
+}
+
+func diffAlertmanagerConfig(k, oldValue, newValue string, d *schema.ResourceData) bool {
+	// If we cannot unmarshal, as we cannot return an error, let's say there is a difference
+	oldConfig, err := formatAlertmanagerConfig(oldValue)
+	if err != nil {
+		tflog.Warn(context.Background(), "Failed to unmarshal oldConfig value")
+		tflog.Debug(context.Background(), err.Error())
+		return false
+	}
+
+	newConfig, err := formatAlertmanagerConfig(newValue)
+	if err != nil {
+		tflog.Warn(context.Background(), "Failed to unmarshal newConfig value")
+		tflog.Debug(context.Background(), err.Error())
+		return false
+	}
+
+	return oldConfig == newConfig
+}
+
+func createAlertmanagerConfig(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	client, err := alertmanagerClient(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	configYaml := d.Get("config_yaml").(string)
+	templates := stringValueMap(d.Get("template_files").(map[string]interface{}))
+
+	err = client.CreateAlertmanagerConfig(ctx, configYaml, templates)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(alertmanagerConfigID(d))
+	return readAlertmanagerConfig(ctx, d, meta)
+}
+
+func readAlertmanagerConfig(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client, err := alertmanagerClient(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	configYaml, templates, err := client.GetAlertmanagerConfig(ctx)
+	if errors.Is(err, cortextool.ErrResourceNotFound) {
+		tflog.Warn(ctx, "Alertmanager configuration not found, removing it from the state")
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	configString, err := formatAlertmanagerConfig(configYaml)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.Set("config_yaml", configString)
+	d.Set("template_files", templates)
+	return diags
+}
+
+func deleteAlertmanagerConfig(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client, err := alertmanagerClient(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = client.DeleteAlermanagerConfig(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return diags
+}
+
+// alertmanagerConfigID returns the ID of the tenant's Alertmanager configuration, there is only one per tenant.
+func alertmanagerConfigID(d attributeGetter) string {
+	return tenantResourceID(d, "alertmanager")
+}
+
+// importAlertmanagerConfig accepts "alertmanager", optionally prefixed with "tenant:" to manage the
+// configuration of another tenant than the provider's one.
+func importAlertmanagerConfig(_ context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	id := d.Id()
+	if tenant, rest, found := cutTenantID(id); found {
+		setImportedTenantID(d, meta, tenant)
+		id = rest
+	}
+	if id != "alertmanager" {
+		return nil, fmt.Errorf("unexpected ID %q, expected alertmanager or tenant:alertmanager", d.Id())
+	}
+
+	d.SetId(alertmanagerConfigID(d))
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,109 @@
+package cortextool
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"os"
+	"regexp"
+	"testing"
+)
+
+const expectedAlertmanagerConfig = `receivers:
+    - name: default
+    - name: sre
+      webhook_configs:
+        - url: http://alerts.example.com/sre
+route:
+    group_by:
+        - alertname
+        - team
+    receiver: default
+    routes:
+        - matchers:
+            - team="sre"
+          receiver: sre
+templates:
+    - default.tmpl
+`
+
+func TestAccResourceAlertmanagerConfig(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "cortextool_alertmanager_config" "demo" {
+						config_yaml = file("testdata/alertmanager.yaml")
+						template_files = {
+							"default.tmpl" = "{{ define \"default.title\" }}{{ .CommonLabels.alertname }}{{ end }}"
+						}
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_alertmanager_config.demo", "config_yaml", expectedAlertmanagerConfig),
+					resource.TestCheckResourceAttr(
+						"cortextool_alertmanager_config.demo", "template_files.%", "1"),
+				),
+			},
+			// Reformatting the configuration must not yield any change
+			{
+				Config: `
+					resource "cortextool_alertmanager_config" "demo" {
+						config_yaml = file("testdata/alertmanager_reformatted.yaml")
+						template_files = {
+							"default.tmpl" = "{{ define \"default.title\" }}{{ .CommonLabels.alertname }}{{ end }}"
+						}
+					}
+					`,
+				PlanOnly: true,
+			},
+			{
+				ResourceName:      "cortextool_alertmanager_config.demo",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceAlertmanagerConfigTenant(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "cortextool_alertmanager_config" "team_d" {
+						tenant_id = "team-d"
+						config_yaml = file("testdata/alertmanager.yaml")
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_alertmanager_config.team_d", "id", "team-d:alertmanager"),
+					resource.TestCheckResourceAttr(
+						"cortextool_alertmanager_config.team_d", "config_yaml", expectedAlertmanagerConfig),
+				),
+			},
+			{
+				ResourceName:      "cortextool_alertmanager_config.team_d",
+				ImportState:       true,
+				ImportStateId:     "team-d:alertmanager",
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:  "cortextool_alertmanager_config.team_d",
+				ImportState:   true,
+				ImportStateId: "team-d",
+				ExpectError:   regexp.MustCompile(`expected alertmanager or tenant:alertmanager`),
+			},
+		},
+	})
+}
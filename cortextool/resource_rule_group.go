@@ -0,0 +1,306 @@
+package cortextool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	cortextool "github.com/grafana/cortex-tools/pkg/client"
+	"github.com/grafana/cortex-tools/pkg/rules"
+	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"gopkg.in/yaml.v3"
+)
+
+func resourceRuleGroup() *schema.Resource {
+	return &schema.Resource{
+		Description: `
+Manages a single group of a rule namespace, leaving the namespace's other groups untouched.
+
+* [Official documentation](https://grafana.com/docs/loki/latest/rules/)
+* [HTTP API](https://grafana.com/docs/loki/latest/api/#ruler)
+`,
+
+		CreateContext: createRuleGroup,
+		ReadContext:   readRuleGroup,
+		UpdateContext: updateRuleGroup,
+		DeleteContext: deleteRuleGroup,
+		CustomizeDiff: customizeRuleGroupDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: importRuleGroup,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"namespace": {
+				Description: "The name of the namespace the group belongs to",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Description: "The name of the group",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"tenant_id": {
+				Description: "The tenant the group belongs to. Defaults to the provider's `tenant_id`.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"config_yaml": {
+				Description:      "The group's rules definition, in the same format as a group of a namespace. The `name` key may be omitted, it must match `name` otherwise.",
+				Type:             schema.TypeString,
+				ValidateDiagFunc: validateRuleGroupYaml,
+				DiffSuppressFunc: diffRuleGroup,
+				Required:         true,
+			},
+			"backend": {
+				Description:  "Ruler backend the rules are written for, one of `loki`, `cortex` or `mimir`. It selects the query language used to lint and validate the rules. Defaults to the provider's `backend`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice(backends, false),
+			},
+		},
+	}
+}
+
+func ruleGroupID(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+func parseRuleGroupYaml(configYaml string) (rwrulefmt.RuleGroup, error) {
+	var group rwrulefmt.RuleGroup
+	err := yaml.Unmarshal([]byte(configYaml), &group)
+	return group, err
+}
+
+func getRuleGroupFromYaml(configYaml string, name string, backend string) (rwrulefmt.RuleGroup, error) {
+	group, err := parseRuleGroupYaml(configYaml)
+	if err != nil {
+		return group, err
+	}
+	if group.Name != "" && group.Name != name {
+		return group, fmt.Errorf("the group is named %q in the definition but %q in the resource", group.Name, name)
+	}
+	group.Name = name
+
+	namespace := rules.RuleNamespace{Groups: []rwrulefmt.RuleGroup{group}}
+	_, _, err = namespace.LintExpressions(lintBackend(backend))
+	return namespace.Groups[0], err
+}
+
+func formatRuleGroup(group rwrulefmt.RuleGroup) string {
+	newYamlBytes, _ := yaml.Marshal(&group)
+	return string(newYamlBytes)
+}
+
+func validateRuleGroupYaml(config any, k cty.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	configYaml := config.(string)
+	group, err := parseRuleGroupYaml(configYaml)
+	if err != nil {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Group definition is not valid.",
+				Detail:        err.Error(),
+				AttributePath: k,
+			},
+		}
+	}
+	// The group name is set by the resource's name attribute
+	ruleNamespace := rules.RuleNamespace{Groups: []rwrulefmt.RuleGroup{group}}
+	diags = append(diags, ruleIssuesDiagnostics(checkRuleGroups(ruleNamespace), diag.Error, k)...)
+	diags = append(diags, ruleIssuesDiagnostics(checkRuleTemplates(ruleNamespace), diag.Error, k)...)
+	return diags
+}
+
+func diffRuleGroup(k, oldValue, newValue string, d *schema.ResourceData) bool {
+	name := d.Get("name").(string)
+	backend := d.Get("backend").(string)
+
+	// If we cannot unmarshal, as we cannot return an error, let's say there is a difference
+	oldGroup, err := getRuleGroupFromYaml(oldValue, name, backend)
+	if err != nil {
+		tflog.Warn(context.Background(), "Failed to unmarshal oldGroup value")
+		tflog.Debug(context.Background(), err.Error())
+		return false
+	}
+
+	newGroup, err := getRuleGroupFromYaml(newValue, name, backend)
+	if err != nil {
+		tflog.Warn(context.Background(), "Failed to unmarshal newGroup value")
+		tflog.Debug(context.Background(), err.Error())
+		return false
+	}
+
+	return rules.CompareGroups(oldGroup, newGroup) == nil
+}
+
+func customizeRuleGroupDiff(_ context.Context, d *schema.ResourceDiff, meta any) error {
+	if err := setDefaultBackend(d, meta); err != nil {
+		return err
+	}
+
+	if !d.NewValueKnown("config_yaml") || !d.NewValueKnown("name") || !d.NewValueKnown("backend") {
+		return nil
+	}
+	backend := d.Get("backend").(string)
+	group, err := parseRuleGroupYaml(d.Get("config_yaml").(string))
+	if err != nil {
+		return fmt.Errorf("group definition is not valid: %w", err)
+	}
+	group.Name = d.Get("name").(string)
+	ruleNamespace := rules.RuleNamespace{Namespace: d.Get("namespace").(string), Groups: []rwrulefmt.RuleGroup{group}}
+	if err := joinRuleIssues(checkRuleNamespace(ruleNamespace, backend, meta)); err != nil {
+		return fmt.Errorf("group definition is not valid for the %s backend:\n%w", backend, err)
+	}
+	if _, err := getRuleGroupFromYaml(d.Get("config_yaml").(string), d.Get("name").(string), backend); err != nil {
+		return fmt.Errorf("group definition is not valid for the %s backend: %w", backend, err)
+	}
+	return nil
+}
+
+// getRuleGroupRemote returns the group from the ruler, or nil when it does not exist.
+func getRuleGroupRemote(ctx context.Context, d *schema.ResourceData, meta any) (*rwrulefmt.RuleGroup, error) {
+	client, err := ruleClient(ctx, d, meta)
+	if err != nil {
+		return nil, err
+	}
+	namespace := d.Get("namespace").(string)
+	name := d.Get("name").(string)
+
+	ruleGroups, err := client.ListRules(ctx, namespace)
+	if errors.Is(err, cortextool.ErrResourceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range ruleGroups[namespace] {
+		if group.Name == name {
+			return &group, nil
+		}
+	}
+	return nil, nil
+}
+
+func createRuleGroup(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	namespace := d.Get("namespace").(string)
+	name := d.Get("name").(string)
+
+	// Do not silently take over a group managed elsewhere
+	remoteGroup, err := getRuleGroupRemote(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if remoteGroup != nil {
+		return diag.Errorf("group %q already exists in namespace %q, import it with the ID %q to manage it",
+			name, namespace, tenantResourceID(d, ruleGroupID(namespace, name)))
+	}
+
+	group, err := putRuleGroup(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(tenantResourceID(d, ruleGroupID(namespace, name)))
+	return append(ruleGroupConventionsDiagnostics(d, meta, group), readRuleGroup(ctx, d, meta)...)
+}
+
+func readRuleGroup(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	group, err := getRuleGroupRemote(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if group == nil {
+		tflog.Warn(ctx, "Rule group not found, removing it from the state", map[string]any{"id": d.Id()})
+		d.SetId("")
+		return diags
+	}
+
+	d.Set("config_yaml", formatRuleGroup(*group))
+	d.Set("backend", resourceBackend(d, meta))
+	return diags
+}
+
+func updateRuleGroup(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	group, err := putRuleGroup(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return append(ruleGroupConventionsDiagnostics(d, meta, group), readRuleGroup(ctx, d, meta)...)
+}
+
+func ruleGroupConventionsDiagnostics(d *schema.ResourceData, meta any, group rwrulefmt.RuleGroup) diag.Diagnostics {
+	ruleNamespace := rules.RuleNamespace{Namespace: d.Get("namespace").(string), Groups: []rwrulefmt.RuleGroup{group}}
+	return ruleConventionsDiagnostics(ruleNamespace, meta)
+}
+
+// putRuleGroup creates or replaces the group and returns it.
+func putRuleGroup(ctx context.Context, d *schema.ResourceData, meta any) (rwrulefmt.RuleGroup, error) {
+	client, err := ruleClient(ctx, d, meta)
+	if err != nil {
+		return rwrulefmt.RuleGroup{}, err
+	}
+	namespace := d.Get("namespace").(string)
+	name := d.Get("name").(string)
+	configYaml := d.Get("config_yaml").(string)
+
+	group, err := getRuleGroupFromYaml(configYaml, name, resourceBackend(d, meta))
+	if err != nil {
+		return group, err
+	}
+	// The plan may have been made without the policy
+	if err := joinRuleIssues(meta.(*providerData).policy.checkRuleGroup(namespace, group)); err != nil {
+		return group, fmt.Errorf("the rules break the policy:\n%w", err)
+	}
+	return group, client.CreateRuleGroup(ctx, namespace, group)
+}
+
+func deleteRuleGroup(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client, err := ruleClient(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	namespace := d.Get("namespace").(string)
+	name := d.Get("name").(string)
+
+	err = client.DeleteRuleGroup(ctx, namespace, name)
+	if err != nil && !errors.Is(err, cortextool.ErrResourceNotFound) {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return diags
+}
+
+// importRuleGroup accepts namespace/group, optionally prefixed with "tenant:" to manage the group
+// for another tenant than the provider's one.
+func importRuleGroup(_ context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	id := d.Id()
+	if tenant, rest, found := cutTenantID(id); found {
+		setImportedTenantID(d, meta, tenant)
+		id = rest
+	}
+	namespace, name, found := strings.Cut(id, "/")
+	if !found || namespace == "" || name == "" {
+		return nil, fmt.Errorf("unexpected ID %q, expected namespace/group or tenant:namespace/group", d.Id())
+	}
+
+	d.Set("namespace", namespace)
+	d.Set("name", name)
+	d.SetId(tenantResourceID(d, ruleGroupID(namespace, name)))
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,182 @@
+package cortextool
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"os"
+	"regexp"
+	"testing"
+)
+
+const expectedRuleGroupConfig = `name: sre
+rules:
+    - alert: LogErrorMessages
+      expr: '(sum(rate({deployment="grafana-agent-traces"} |= "level=error"[1m])) > 0.1)'
+      for: 3m
+      labels:
+        team: sre
+`
+
+const expectedRuleGroupConfigAfterUpdate = `name: sre
+rules:
+    - alert: LogErrorMessages
+      expr: |-
+        (sum(rate({deployment="grafana-agent-traces"} |= "level=error"[1m])) > 0.5)
+      for: 5m
+      labels:
+        team: sre
+`
+
+func TestAccResourceRuleGroup(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "cortextool_rule_group" "sre" {
+						namespace = "shared"
+						name = "sre"
+						config_yaml = file("testdata/rule_group.yaml")
+					}
+
+					resource "cortextool_rule_group" "agent" {
+						namespace = "shared"
+						name = "grafana-agent"
+						config_yaml = file("testdata/rule_group2.yaml")
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_group.sre", "id", "shared/sre"),
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_group.sre", "config_yaml", expectedRuleGroupConfig),
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_group.agent", "id", "shared/grafana-agent"),
+				),
+			},
+			// Updating one group leaves the other one alone
+			{
+				Config: `
+					resource "cortextool_rule_group" "sre" {
+						namespace = "shared"
+						name = "sre"
+						config_yaml = file("testdata/rule_group2.yaml")
+					}
+
+					resource "cortextool_rule_group" "agent" {
+						namespace = "shared"
+						name = "grafana-agent"
+						config_yaml = file("testdata/rule_group2.yaml")
+					}
+
+					data "cortextool_rule_namespace" "shared" {
+						namespace = "shared"
+						depends_on = [cortextool_rule_group.sre, cortextool_rule_group.agent]
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_group.sre", "config_yaml", expectedRuleGroupConfigAfterUpdate),
+					resource.TestCheckResourceAttr(
+						"data.cortextool_rule_namespace.shared", "group_names.#", "2"),
+				),
+			},
+			{
+				ResourceName:      "cortextool_rule_group.sre",
+				ImportState:       true,
+				ImportStateId:     "shared/sre",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceRuleGroupNameMismatch(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "cortextool_rule_group" "sre" {
+						namespace = "shared-mismatch"
+						name = "other"
+						config_yaml = file("testdata/rule_group.yaml")
+					}
+					`,
+				ExpectError: regexp.MustCompile(`the group is named "sre" in the definition but "other" in the resource`),
+			},
+		},
+	})
+}
+
+func TestAccResourceRuleGroupTenant(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "cortextool_rule_group" "sre" {
+						namespace = "shared-tenant"
+						name = "sre"
+						config_yaml = file("testdata/rule_group.yaml")
+					}
+
+					resource "cortextool_rule_group" "team_b" {
+						namespace = "shared-tenant"
+						name = "sre"
+						tenant_id = "team-b"
+						config_yaml = file("testdata/rule_group.yaml")
+					}
+
+					resource "cortextool_rule_group" "slashed" {
+						namespace = "shared-tenant"
+						name = "sre/critical"
+						config_yaml = <<-EOT
+							rules:
+							  - alert: LogErrorMessages
+							    expr: 'sum(rate({deployment="grafana-agent-traces"} |= "level=error" [1m])) > 0.1'
+						EOT
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_group.sre", "id", "shared-tenant/sre"),
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_group.team_b", "id", "team-b:shared-tenant/sre"),
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_group.slashed", "id", "shared-tenant/sre/critical"),
+				),
+			},
+			{
+				ResourceName:      "cortextool_rule_group.team_b",
+				ImportState:       true,
+				ImportStateId:     "team-b:shared-tenant/sre",
+				ImportStateVerify: true,
+			},
+			// The group name keeps the segments after the namespace
+			{
+				ResourceName:      "cortextool_rule_group.slashed",
+				ImportState:       true,
+				ImportStateId:     "shared-tenant/sre/critical",
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:  "cortextool_rule_group.team_b",
+				ImportState:   true,
+				ImportStateId: "team-b:",
+				ExpectError:   regexp.MustCompile(`expected namespace/group or tenant:namespace/group`),
+			},
+		},
+	})
+}
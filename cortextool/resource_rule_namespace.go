@@ -2,14 +2,20 @@ package cortextool
 
 import (
 	"context"
-	"crypto/sha256"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	cortextool "github.com/grafana/cortex-tools/pkg/client"
 	"github.com/grafana/cortex-tools/pkg/rules"
+	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"golang.org/x/exp/slices"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"gopkg.in/yaml.v3"
 )
 
@@ -24,8 +30,26 @@ func resourceRuleNamespace() *schema.Resource {
 		ReadContext:   readRuleNamespace,
 		UpdateContext: updateRuleNamespace,
 		DeleteContext: deleteRuleNamespace,
+		CustomizeDiff: customizeRuleNamespaceDiff,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importRuleNamespace,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Read:   schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		// Version 0 used a hash of the namespace as the ID
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceRuleNamespaceV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: upgradeRuleNamespaceStateV0,
+			},
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -33,50 +57,142 @@ func resourceRuleNamespace() *schema.Resource {
 				Description: "The name of the namespace to create in Grafana",
 				Type:        schema.TypeString,
 				Required:    true,
+				ForceNew:    true,
+			},
+			"tenant_id": {
+				Description: "The tenant the namespace belongs to. Defaults to the provider's `tenant_id`.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
 			},
 			"config_yaml": {
-				Description:      "The namespace's groups rules definition to create",
+				Description:      "The namespace's groups rules definition to create. Exactly one of `config_yaml` or `group` must be set, it holds the normalized rules when `group` is used.",
 				Type:             schema.TypeString,
-				StateFunc:        stateFunction,
 				ValidateDiagFunc: validateNamespaceYaml,
 				DiffSuppressFunc: diffNamespaceRules,
-				Required:         true,
+				Optional:         true,
+				Computed:         true,
+				ExactlyOneOf:     []string{"config_yaml", "group"},
+			},
+			"group": {
+				Description:  "The namespace's groups, as an alternative to `config_yaml`",
+				Type:         schema.TypeList,
+				Optional:     true,
+				Elem:         ruleGroupSchema(),
+				ExactlyOneOf: []string{"config_yaml", "group"},
+			},
+			"group_hashes": {
+				Description: "The sha256 of each group's normalized rules, keyed by group name",
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+			},
+			"backend": {
+				Description:  "Ruler backend the rules are written for, one of `loki`, `cortex` or `mimir`. It selects the query language used to lint and validate the rules. Defaults to the provider's `backend`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice(backends, false),
+			},
+			"state_format": {
+				Description:  "How the rules are stored in the state's `config_yaml`, one of `yaml` for the normalized rules, `sha256` for their hash or `group_sha256` for the hash of each group. Defaults to `sha256` when the provider's `store_rules_sha256` is set, `yaml` otherwise.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice(stateFormats, false),
 			},
 		},
 	}
 }
 
-func getRuleNamespaceFromYaml(configYaml string) (rules.RuleNamespace, error) {
+func resourceRuleNamespaceV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"namespace": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"config_yaml": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"backend": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// sha256HexRegexp matches the config_yaml stored by the sha256 mode of the version 0.
+var sha256HexRegexp = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// upgradeRuleNamespaceStateV0 keys the state by namespace and records the state format the version 0
+// stored config_yaml in, as it only held either the normalized rules or their sha256.
+func upgradeRuleNamespaceStateV0(_ context.Context, rawState map[string]any, _ any) (map[string]any, error) {
+	if namespace, ok := rawState["namespace"].(string); ok && namespace != "" {
+		rawState["id"] = namespace
+	}
+	rawState["state_format"] = stateFormatYaml
+	if configYaml, ok := rawState["config_yaml"].(string); ok && sha256HexRegexp.MatchString(configYaml) {
+		rawState["state_format"] = stateFormatSha256
+	}
+	return rawState, nil
+}
+
+func parseRuleNamespaceYaml(configYaml string) (rules.RuleNamespace, error) {
 	var namespace rules.RuleNamespace
 	err := yaml.Unmarshal([]byte(configYaml), &namespace)
+	return namespace, err
+}
+
+func getRuleNamespaceFromYaml(configYaml string, backend string) (rules.RuleNamespace, error) {
+	namespace, err := parseRuleNamespaceYaml(configYaml)
 	if err != nil {
 		return namespace, err
 	}
-	namespace.LintExpressions(rules.LokiBackend)
-	return namespace, nil
+	_, _, err = namespace.LintExpressions(lintBackend(backend))
+	return namespace, err
 }
 
-func formatRuleNamespace(ruleNamespace rules.RuleNamespace) string {
-	newYamlBytes, _ := yaml.Marshal(&ruleNamespace)
-
-	if storeRulesSha256 {
-		configHash := sha256.Sum256(newYamlBytes)
-		return fmt.Sprintf("%x", configHash[:])
+// parseRuleNamespaceConfig returns the namespace defined either by config_yaml or by the group
+// blocks, without linting its expressions.
+func parseRuleNamespaceConfig(d attributeGetter) (rules.RuleNamespace, error) {
+	var namespace rules.RuleNamespace
+	if rawGroups := d.Get("group").([]any); len(rawGroups) > 0 {
+		groups, err := expandRuleGroups(rawGroups)
+		if err != nil {
+			return namespace, err
+		}
+		namespace.Groups = groups
+	} else {
+		var err error
+		if namespace, err = parseRuleNamespaceYaml(d.Get("config_yaml").(string)); err != nil {
+			return namespace, err
+		}
 	}
-
-	return string(newYamlBytes)
+	namespace.Namespace = d.Get("namespace").(string)
+	return namespace, nil
 }
 
-func stateFunction(meta any) string {
-	configYaml := meta.(string)
-	namespace, _ := getRuleNamespaceFromYaml(configYaml)
-	return formatRuleNamespace(namespace)
+// getRuleNamespaceFromConfig returns the namespace defined either by config_yaml or by the group blocks.
+func getRuleNamespaceFromConfig(d attributeGetter, backend string) (rules.RuleNamespace, error) {
+	namespace, err := parseRuleNamespaceConfig(d)
+	if err != nil {
+		return namespace, err
+	}
+	_, _, err = namespace.LintExpressions(lintBackend(backend))
+	return namespace, err
 }
 
+// validateNamespaceYaml checks the YAML syntax, the groups definitions and the alerts templates.
+// Expressions are parsed against the resource's backend, and the conventions checked against the
+// provider's settings, in customizeRuleNamespaceDiff.
 func validateNamespaceYaml(config any, k cty.Path) diag.Diagnostics {
 	var diags diag.Diagnostics
 	configYaml := config.(string)
-	_, err := getRuleNamespaceFromYaml(configYaml)
+	ruleNamespace, err := parseRuleNamespaceYaml(configYaml)
 	if err != nil {
 		return diag.Diagnostics{
 			diag.Diagnostic{
@@ -87,21 +203,31 @@ func validateNamespaceYaml(config any, k cty.Path) diag.Diagnostics {
 			},
 		}
 	}
+	diags = append(diags, ruleIssuesDiagnostics(checkRuleGroups(ruleNamespace), diag.Error, k)...)
+	diags = append(diags, ruleIssuesDiagnostics(checkRuleTemplates(ruleNamespace), diag.Error, k)...)
 	return diags
 }
 
 func diffNamespaceRules(k, oldValue, newValue string, d *schema.ResourceData) bool {
+	backend := d.Get("backend").(string)
+
 	// If we cannot unmarshal, as we cannot return an error, let's say there is a difference
-	oldNamespace, err := getRuleNamespaceFromYaml(oldValue)
+	newNamespace, err := getRuleNamespaceFromYaml(newValue, backend)
 	if err != nil {
-		tflog.Warn(context.Background(), "Failed to unmarshal oldGroup value")
+		tflog.Warn(context.Background(), "Failed to unmarshal newGroup value")
 		tflog.Debug(context.Background(), err.Error())
 		return false
 	}
 
-	newNamespace, err := getRuleNamespaceFromYaml(newValue)
+	// The state only holds hashes of the normalized rules
+	if stateFormat := d.Get("state_format").(string); stateFormat != "" && stateFormat != stateFormatYaml {
+		newNamespace.Namespace = d.Get("namespace").(string)
+		return oldValue == formatRuleNamespace(newNamespace, stateFormat)
+	}
+
+	oldNamespace, err := getRuleNamespaceFromYaml(oldValue, backend)
 	if err != nil {
-		tflog.Warn(context.Background(), "Failed to unmarshal newGroup value")
+		tflog.Warn(context.Background(), "Failed to unmarshal oldGroup value")
 		tflog.Debug(context.Background(), err.Error())
 		return false
 	}
@@ -109,9 +235,57 @@ func diffNamespaceRules(k, oldValue, newValue string, d *schema.ResourceData) bo
 	return rules.CompareNamespaces(oldNamespace, newNamespace).State == rules.Unchanged
 }
 
+func customizeRuleNamespaceDiff(_ context.Context, d *schema.ResourceDiff, meta any) error {
+	if err := setDefaultBackend(d, meta); err != nil {
+		return err
+	}
+	if err := setDefaultStateFormat(d, meta); err != nil {
+		return err
+	}
+
+	// config_yaml holds the rules built from the group blocks
+	if len(d.Get("group").([]any)) > 0 && d.HasChange("group") {
+		if err := d.SetNewComputed("config_yaml"); err != nil {
+			return err
+		}
+	}
+
+	rulesChanged := d.HasChange("config_yaml") || d.HasChange("group")
+
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.GetAttr("config_yaml").IsWhollyKnown() ||
+		!config.GetAttr("group").IsWhollyKnown() || !d.NewValueKnown("backend") {
+		if rulesChanged {
+			return d.SetNewComputed("group_hashes")
+		}
+		return nil
+	}
+	backend := d.Get("backend").(string)
+	ruleNamespace, err := parseRuleNamespaceConfig(d)
+	if err != nil {
+		return fmt.Errorf("namespace definition is not valid: %w", err)
+	}
+	// Report every invalid rule rather than the first one the linter stops at
+	if err := joinRuleIssues(checkRuleNamespace(ruleNamespace, backend, meta)); err != nil {
+		return fmt.Errorf("namespace definition is not valid for the %s backend:\n%w", backend, err)
+	}
+	if _, _, err := ruleNamespace.LintExpressions(lintBackend(backend)); err != nil {
+		return fmt.Errorf("namespace definition is not valid for the %s backend: %w", backend, err)
+	}
+
+	// Plan the hashes so that the plan pinpoints the changed groups
+	if rulesChanged {
+		return d.SetNew("group_hashes", ruleGroupHashes(ruleNamespace.Groups))
+	}
+	return nil
+}
+
 func getRuleNamespaceRemote(ctx context.Context, d *schema.ResourceData, meta any) (
 	rules.RuleNamespace, error) {
-	client := *meta.(*providerData).cli
+	client, err := ruleClient(ctx, d, meta)
+	if err != nil {
+		return rules.RuleNamespace{}, err
+	}
 	namespace := d.Get("namespace").(string)
 
 	ruleGroups, err := client.ListRules(ctx, namespace)
@@ -125,87 +299,119 @@ func getRuleNamespaceRemote(ctx context.Context, d *schema.ResourceData, meta an
 	}, nil
 }
 
-func createRuleNamespace(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	client := *meta.(*providerData).cli
+// applyRuleNamespace only pushes the namespace's groups which differ from the remote ones and, when
+// deleteRemoved is set, deletes the remote groups it does not define. On failure, the changed groups
+// are restored from a snapshot of the remote namespace taken beforehand, so that the ruler is not
+// left with a mix of previous and new groups.
+func applyRuleNamespace(ctx context.Context, d *schema.ResourceData, meta any,
+	ruleNamespace rules.RuleNamespace, deleteRemoved bool) diag.Diagnostics {
+	client, err := ruleClient(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	namespace := d.Get("namespace").(string)
-	configYaml := d.Get("config_yaml").(string)
 
-	ruleNamespace, err := getRuleNamespaceFromYaml(configYaml)
-	if err != nil {
+	snapshot, err := getRuleNamespaceRemote(ctx, d, meta)
+	if err != nil && !errors.Is(err, cortextool.ErrResourceNotFound) {
 		return diag.FromErr(err)
 	}
+	parallelism := meta.(*providerData).maxParallelRequests
+	tx := newRuleNamespaceTransaction(client, namespace, parallelism, snapshot.Groups)
 
-	for _, group := range ruleNamespace.Groups {
-		err = client.CreateRuleGroup(ctx, namespace, group)
-		if err != nil {
-			return diag.FromErr(err)
-		}
+	change := rules.CompareNamespaces(snapshot, ruleNamespace)
+	var groupsDeleted []string
+	if deleteRemoved {
+		groupsDeleted = ruleGroupNames(change.GroupsDeleted)
 	}
+	tflog.Info(ctx, "Applying rule namespace changes", map[string]any{
+		"namespace":   namespace,
+		"created":     len(change.GroupsCreated),
+		"updated":     len(change.GroupsUpdated),
+		"deleted":     len(groupsDeleted),
+		"parallelism": parallelism,
+	})
 
-	d.SetId(hash(namespace))
-	return readRuleNamespace(ctx, d, meta)
+	groupsCreated := make([]rwrulefmt.RuleGroup, 0, len(change.GroupsUpdated)+len(change.GroupsCreated))
+	for _, group := range change.GroupsUpdated {
+		groupsCreated = append(groupsCreated, group.New)
+	}
+	groupsCreated = append(groupsCreated, change.GroupsCreated...)
+
+	// The plan may have been made without the policy
+	policy := meta.(*providerData).policy
+	var issues []ruleIssue
+	for _, group := range groupsCreated {
+		issues = append(issues, policy.checkRuleGroup(namespace, group)...)
+	}
+	if err := joinRuleIssues(issues); err != nil {
+		return diag.Errorf("the rules break the policy:\n%s", err)
+	}
+
+	if errs := tx.apply(ctx, groupsCreated, groupsDeleted); len(errs) > 0 {
+		return tx.rollbackDiagnostics(ctx, errs)
+	}
+	return nil
 }
 
-func readRuleNamespace(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	var diags diag.Diagnostics
+func createRuleNamespace(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	namespace := d.Get("namespace").(string)
 
-	ruleNamespace, err := getRuleNamespaceRemote(ctx, d, meta)
+	ruleNamespace, err := getRuleNamespaceFromConfig(d, resourceBackend(d, meta))
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	configString := formatRuleNamespace(ruleNamespace)
 
-	d.Set("config_yaml", configString)
-	return diags
+	if diags := applyRuleNamespace(ctx, d, meta, ruleNamespace, false); diags.HasError() {
+		return diags
+	}
+
+	d.SetId(tenantResourceID(d, namespace))
+	return append(ruleConventionsDiagnostics(ruleNamespace, meta), readRuleNamespace(ctx, d, meta)...)
 }
 
-func updateRuleNamespace(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+func readRuleNamespace(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	var diags diag.Diagnostics
-	client := *meta.(*providerData).cli
-	namespace := d.Get("namespace").(string)
-	configYaml := d.Get("config_yaml").(string)
 
-	errDiag := createRuleNamespace(ctx, d, meta)
-	if errDiag != nil {
-		return errDiag
-	}
-	// Clean up the rules which need to be updated have been so with createRuleNamespace,
-	// we still need to delete the rules which have been removed from the definition.
-	ruleNamespace, err := getRuleNamespaceFromYaml(configYaml)
+	ruleNamespace, err := getRuleNamespaceRemote(ctx, d, meta)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	stateFormat := resourceStateFormat(d, meta)
+	configString := formatRuleNamespace(ruleNamespace, stateFormat)
 
-	var nsGroupNames []string
-	for _, group := range ruleNamespace.Groups {
-		nsGroupNames = append(nsGroupNames, group.Name)
+	// Only refresh the group blocks when they are used, config_yaml is always set
+	if len(d.Get("group").([]any)) > 0 {
+		if err := d.Set("group", flattenRuleGroups(ruleNamespace.Groups)); err != nil {
+			return diag.FromErr(err)
+		}
 	}
+	d.Set("config_yaml", configString)
+	d.Set("group_hashes", ruleGroupHashes(ruleNamespace.Groups))
+	d.Set("backend", resourceBackend(d, meta))
+	d.Set("state_format", stateFormat)
+	return diags
+}
 
-	// the ones which are configured in the rulers as per readRuleNamespace
-	localNamespaces, err := getRuleNamespaceRemote(ctx, d, meta)
+func updateRuleNamespace(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	ruleNamespace, err := getRuleNamespaceFromConfig(d, resourceBackend(d, meta))
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	currentGroupsNames := make([]string, 0, len(localNamespaces.Groups))
-	for _, group := range localNamespaces.Groups {
-		currentGroupsNames = append(currentGroupsNames, group.Name)
-	}
 
-	// All groups present in Loki but not in the YAML definition must be deleted
-	for _, name := range currentGroupsNames {
-		if !slices.Contains(nsGroupNames, name) {
-			errRaw := client.DeleteRuleGroup(ctx, namespace, name)
-			if err != nil {
-				return diag.FromErr(errRaw)
-			}
-		}
+	if diags := applyRuleNamespace(ctx, d, meta, ruleNamespace, true); diags.HasError() {
+		// The ruler has been restored, keep the previous state
+		d.Partial(true)
+		return diags
 	}
-	return diags
+	return append(ruleConventionsDiagnostics(ruleNamespace, meta), readRuleNamespace(ctx, d, meta)...)
 }
 
 func deleteRuleNamespace(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	var diags diag.Diagnostics
-	client := *meta.(*providerData).cli
+	client, err := ruleClient(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	namespace := d.Get("namespace").(string)
 
 	ruleNamespace, err := getRuleNamespaceRemote(ctx, d, meta)
@@ -213,14 +419,52 @@ func deleteRuleNamespace(ctx context.Context, d *schema.ResourceData, meta any)
 		return diag.FromErr(err)
 	}
 
-	for _, groupName := range ruleNamespace.Groups {
-		err :=
-			client.DeleteRuleGroup(ctx, namespace, groupName.Name)
-		if err != nil {
-			return diag.FromErr(err)
+	errs := forEachParallel(meta.(*providerData).maxParallelRequests, len(ruleNamespace.Groups), false, func(i int) error {
+		name := ruleNamespace.Groups[i].Name
+		if err := client.DeleteRuleGroup(ctx, namespace, name); err != nil {
+			return fmt.Errorf("deleting group %q: %w", name, err)
 		}
+		return nil
+	})
+	for _, err := range errs {
+		diags = append(diags, diag.FromErr(err)...)
+	}
+	if diags.HasError() {
+		return diags
 	}
 
 	d.SetId("")
 	return diags
 }
+
+// importRuleNamespace accepts the namespace name, optionally prefixed with "tenant:" to manage it
+// for another tenant than the provider's one. The provider's tenant may also prefix it as
+// tenant/namespace, which a namespace containing a "/" requires.
+func importRuleNamespace(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	namespace := d.Id()
+	if tenant, name, found := cutTenantID(d.Id()); found {
+		setImportedTenantID(d, meta, tenant)
+		namespace = name
+	} else if tenant, name, found := strings.Cut(d.Id(), "/"); found {
+		if tenantID := meta.(*providerData).tenantID; tenant != tenantID {
+			return nil, fmt.Errorf("unexpected tenant %q in ID %q, the provider is configured for tenant %q, use %q for another tenant",
+				tenant, d.Id(), tenantID, tenant+":"+name)
+		}
+		namespace = name
+	}
+	if namespace == "" {
+		return nil, fmt.Errorf("unexpected ID %q, expected namespace, tenant:namespace or tenant/namespace", d.Id())
+	}
+	d.Set("namespace", namespace)
+
+	ruleNamespace, err := getRuleNamespaceRemote(ctx, d, meta)
+	if err != nil && !errors.Is(err, cortextool.ErrResourceNotFound) {
+		return nil, err
+	}
+	if len(ruleNamespace.Groups) == 0 {
+		return nil, fmt.Errorf("namespace %q not found", namespace)
+	}
+
+	d.SetId(tenantResourceID(d, namespace))
+	return []*schema.ResourceData{d}, nil
+}
@@ -2,11 +2,17 @@ package cortextool
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"os"
+	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -47,6 +53,19 @@ groups:
             team: sre
 `
 
+const expectedPromQLConfig = `namespace: node
+groups:
+    - name: node
+      rules:
+        - alert: NodeDown
+          expr: up{job="node"} == 0
+          for: 5m
+          labels:
+            severity: critical
+        - record: instance:node_cpu:rate5m
+          expr: sum by (instance) (rate(node_cpu_seconds_total{mode!="idle"}[5m]))
+`
+
 var testAccProviderFactories map[string]func() (*schema.Provider, error)
 var testAccProvider *schema.Provider
 var testAccProviders map[string]*schema.Provider
@@ -54,7 +73,7 @@ var testAccProviderConfigure sync.Once
 
 func init() {
 	var cc CortexRuleClient
-	cc = NewMockCortexRuleClient()
+	cc = NewMockCortexRuleClient(backendLoki)
 
 	testAccProvider = New("dev", &cc)()
 	testAccProviders = map[string]*schema.Provider{
@@ -70,6 +89,15 @@ func init() {
 	}
 }
 
+// testProviderFactories returns provider factories serving the rules from the given client.
+func testProviderFactories(client CortexRuleClient) map[string]func() (*schema.Provider, error) {
+	return map[string]func() (*schema.Provider, error){
+		"cortextool": func() (*schema.Provider, error) {
+			return New("dev", &client)(), nil
+		},
+	}
+}
+
 // testAccPreCheck verifies required provider testing configuration. It should
 // be present in every acceptance test.
 //
@@ -197,3 +225,882 @@ func TestAccResourceNamespaceWhitespaceChanges(t *testing.T) {
 		os.Unsetenv(envVar)
 	}
 }
+
+func TestAccResourceNamespaceCortexBackend(t *testing.T) {
+	providerFactories := testProviderFactories(NewMockCortexRuleClient(backendCortex))
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "cortextool" {
+						address = "http://localhost:8080"
+						backend = "cortex"
+					}
+
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "node"
+						config_yaml = file("testdata/rules_promql.yaml")
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "backend", "cortex"),
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "config_yaml", expectedPromQLConfig),
+				),
+			},
+			{
+				Config: `
+					provider "cortextool" {
+						address = "http://localhost:8080"
+					}
+
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "node"
+						backend = "cortex"
+						config_yaml = file("testdata/rules_promql.yaml")
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "backend", "cortex"),
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "config_yaml", expectedPromQLConfig),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceNamespaceGroupBlocks(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "grafana-agent-traces-blocks"
+
+						group {
+							name = "grafana-agent"
+							rule {
+								alert = "LogWarnMessages"
+								expr  = "sum(rate({deployment=\"grafana-agent-traces\"} |= ` + "`level=warn`" + ` [1m])) > 0.1"
+								for   = "5m"
+								labels = {
+									team = "sre"
+								}
+							}
+						}
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "group.0.name", "grafana-agent"),
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "group.0.rule.0.for", "5m"),
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "config_yaml", `namespace: grafana-agent-traces-blocks
+groups:
+    - name: grafana-agent
+      rules:
+        - alert: LogWarnMessages
+          expr: (sum(rate({deployment="grafana-agent-traces"} |= "level=warn"[1m])) > 0.1)
+          for: 5m
+          labels:
+            team: sre
+`),
+				),
+			},
+			// Reformatting the expression and the duration must not yield any change
+			{
+				Config: `
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "grafana-agent-traces-blocks"
+
+						group {
+							name = "grafana-agent"
+							rule {
+								alert = "LogWarnMessages"
+								expr  = <<-EOT
+									sum(
+										rate({deployment="grafana-agent-traces"} |= "level=warn" [1m])
+									) > 0.1
+								EOT
+								for   = "300s"
+								labels = {
+									team = "sre"
+								}
+							}
+						}
+					}
+					`,
+				PlanOnly: true,
+			},
+			{
+				Config: `
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "grafana-agent-traces-blocks"
+
+						group {
+							name     = "grafana-agent"
+							interval = "1m"
+							rule {
+								record = "deployment:log_warn_messages:rate1m"
+								expr   = "sum by (deployment) (rate({deployment=\"grafana-agent-traces\"} |= \"level=warn\" [1m]))"
+							}
+						}
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "group.0.interval", "1m"),
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "group.0.rule.#", "1"),
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "group.0.rule.0.record", "deployment:log_warn_messages:rate1m"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceNamespaceGroupBlocksInvalidRule(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "grafana-agent-traces-invalid"
+
+						group {
+							name = "grafana-agent"
+							rule {
+								alert  = "LogWarnMessages"
+								record = "log_warn_messages"
+								expr   = "sum(rate({deployment=\"grafana-agent-traces\"}[1m]))"
+							}
+						}
+					}
+					`,
+				ExpectError: regexp.MustCompile(`exactly one of alert or record must be set`),
+			},
+		},
+	})
+}
+
+func TestAccResourceNamespaceImport(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "grafana-agent-traces-import"
+						config_yaml = file("testdata/rules.yaml")
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "id", "grafana-agent-traces-import"),
+				),
+			},
+			{
+				ResourceName:      "cortextool_rule_namespace.demo",
+				ImportState:       true,
+				ImportStateId:     "grafana-agent-traces-import",
+				ImportStateVerify: true,
+			},
+			// The provider is not configured with a tenant
+			{
+				ResourceName:      "cortextool_rule_namespace.demo",
+				ImportState:       true,
+				ImportStateId:     "/grafana-agent-traces-import",
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:  "cortextool_rule_namespace.demo",
+				ImportState:   true,
+				ImportStateId: "other-tenant/grafana-agent-traces-import",
+				ExpectError:   regexp.MustCompile(`unexpected tenant "other-tenant"`),
+			},
+			// The namespace is looked up in the other tenant
+			{
+				ResourceName:  "cortextool_rule_namespace.demo",
+				ImportState:   true,
+				ImportStateId: "other-tenant:grafana-agent-traces-import",
+				ExpectError:   regexp.MustCompile(`namespace "grafana-agent-traces-import" not found`),
+			},
+			{
+				ResourceName:  "cortextool_rule_namespace.demo",
+				ImportState:   true,
+				ImportStateId: "missing",
+				ExpectError:   regexp.MustCompile(`namespace "missing" not found`),
+			},
+		},
+	})
+}
+
+func TestResourceRuleNamespaceStateUpgradeV0(t *testing.T) {
+	rawState := map[string]any{
+		"id":          hash("grafana-agent-traces"),
+		"namespace":   "grafana-agent-traces",
+		"config_yaml": expectedInitialConfig,
+	}
+
+	upgraded, err := upgradeRuleNamespaceStateV0(context.Background(), rawState, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upgraded["id"] != "grafana-agent-traces" {
+		t.Errorf("unexpected ID %q after upgrade", upgraded["id"])
+	}
+	if upgraded["state_format"] != stateFormatYaml {
+		t.Errorf("unexpected state format %q after upgrade", upgraded["state_format"])
+	}
+}
+
+func TestResourceRuleNamespaceStateUpgradeV0Sha256(t *testing.T) {
+	rawState := map[string]any{
+		"id":          hash("grafana-agent-traces"),
+		"namespace":   "grafana-agent-traces",
+		"config_yaml": "c429c8535b84806d61c9188e6df30f985067b2f74f1daac0e8f5350e6551e53f",
+	}
+
+	upgraded, err := upgradeRuleNamespaceStateV0(context.Background(), rawState, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upgraded["state_format"] != stateFormatSha256 {
+		t.Errorf("unexpected state format %q after upgrade", upgraded["state_format"])
+	}
+	if upgraded["config_yaml"] != rawState["config_yaml"] {
+		t.Errorf("unexpected config_yaml %q after upgrade", upgraded["config_yaml"])
+	}
+}
+
+func TestAccResourceNamespaceStateFormat(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "cortextool" {}
+
+					provider "cortextool" {
+						alias = "hashed"
+						store_rules_sha256 = true
+					}
+
+					resource "cortextool_rule_namespace" "yaml" {
+						namespace = "grafana-agent-traces-yaml"
+						config_yaml = file("testdata/rules2.yaml")
+					}
+
+					resource "cortextool_rule_namespace" "sha256" {
+						provider = cortextool.hashed
+						namespace = "grafana-agent-traces-sha256"
+						config_yaml = file("testdata/rules2.yaml")
+					}
+
+					resource "cortextool_rule_namespace" "group_sha256" {
+						provider = cortextool.hashed
+						namespace = "grafana-agent-traces-group-sha256"
+						config_yaml = file("testdata/rules2.yaml")
+						state_format = "group_sha256"
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.yaml", "state_format", "yaml"),
+					resource.TestMatchResourceAttr(
+						"cortextool_rule_namespace.yaml", "config_yaml", regexp.MustCompile(`^namespace: grafana-agent-traces-yaml\n`)),
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.sha256", "state_format", "sha256"),
+					resource.TestMatchResourceAttr(
+						"cortextool_rule_namespace.sha256", "config_yaml", regexp.MustCompile(`^[0-9a-f]{64}$`)),
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.group_sha256", "state_format", "group_sha256"),
+					resource.TestMatchResourceAttr(
+						"cortextool_rule_namespace.group_sha256", "config_yaml", regexp.MustCompile(`^grafana-agent: [0-9a-f]{64}\n$`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceNamespaceGroupHashes(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	config := func(threshold string) string {
+		return `
+			resource "cortextool_rule_namespace" "demo" {
+				namespace = "grafana-agent-traces-hashes"
+
+				group {
+					name = "errors"
+					rule {
+						alert = "LogErrorMessages"
+						expr  = "sum(rate({deployment=\"grafana-agent-traces\"} |= ` + "`level=error`" + ` [1m])) > 0.1"
+					}
+				}
+
+				group {
+					name = "warnings"
+					rule {
+						alert = "LogWarnMessages"
+						expr  = "sum(rate({deployment=\"grafana-agent-traces\"} |= ` + "`level=warn`" + ` [1m])) > ` + threshold + `"
+					}
+				}
+			}
+			`
+	}
+
+	hashes := map[string]string{}
+	storeHash := func(group string) resource.CheckResourceAttrWithFunc {
+		return func(value string) error {
+			hashes[group] = value
+			return nil
+		}
+	}
+	checkHash := func(group string, changed bool) resource.CheckResourceAttrWithFunc {
+		return func(value string) error {
+			if (value != hashes[group]) != changed {
+				return fmt.Errorf("unexpected hash %s for group %s, previous hash was %s", value, group, hashes[group])
+			}
+			return nil
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("0.1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "group_hashes.%", "2"),
+					resource.TestMatchResourceAttr(
+						"cortextool_rule_namespace.demo", "group_hashes.errors", regexp.MustCompile(`^[0-9a-f]{64}$`)),
+					resource.TestCheckResourceAttrWith(
+						"cortextool_rule_namespace.demo", "group_hashes.errors", storeHash("errors")),
+					resource.TestCheckResourceAttrWith(
+						"cortextool_rule_namespace.demo", "group_hashes.warnings", storeHash("warnings")),
+				),
+			},
+			{
+				Config: config("0.5"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrWith(
+						"cortextool_rule_namespace.demo", "group_hashes.errors", checkHash("errors", false)),
+					resource.TestCheckResourceAttrWith(
+						"cortextool_rule_namespace.demo", "group_hashes.warnings", checkHash("warnings", true)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceNamespaceRollback(t *testing.T) {
+	mock := NewMockCortexRuleClient(backendLoki)
+	providerFactories := testProviderFactories(mock)
+
+	config := func(file string) string {
+		return `
+			provider "cortextool" {
+				address = "http://localhost:8080"
+			}
+
+			resource "cortextool_rule_namespace" "demo" {
+				namespace = "rollback"
+				config_yaml = file("testdata/` + file + `")
+			}
+			`
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("rules_rollback.yaml"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "group_hashes.%", "2"),
+				),
+			},
+			// Modifying errors succeeds but creating failing does not, warnings must not be deleted
+			{
+				PreConfig: func() {
+					mock.groupErrors["failing"] = errors.New("ruler unavailable")
+				},
+				Config:      config("rules_rollback_failing.yaml"),
+				ExpectError: regexp.MustCompile(`Failed to apply namespace "rollback", restored groups: failing, errors`),
+			},
+			// Both the ruler and the state hold the initial groups
+			{
+				PreConfig: func() {
+					delete(mock.groupErrors, "failing")
+				},
+				Config:   config("rules_rollback.yaml"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceNamespaceMinimalChanges(t *testing.T) {
+	mock := NewMockCortexRuleClient(backendLoki)
+	providerFactories := testProviderFactories(mock)
+
+	config := func(file string) string {
+		return `
+			provider "cortextool" {
+				address = "http://localhost:8080"
+			}
+
+			resource "cortextool_rule_namespace" "demo" {
+				namespace = "minimal"
+				config_yaml = file("testdata/` + file + `")
+			}
+			`
+	}
+	checkGroupWrites := func(expected map[string]int) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			for name, writes := range expected {
+				if mock.groupWrites[name] != writes {
+					return fmt.Errorf("group %s was written %d times, expected %d", name, mock.groupWrites[name], writes)
+				}
+			}
+			return nil
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("rules_rollback.yaml"),
+				Check:  checkGroupWrites(map[string]int{"errors": 1, "warnings": 1}),
+			},
+			// Only errors is modified and failing created, warnings is deleted without being written
+			{
+				Config: config("rules_rollback_failing.yaml"),
+				Check: resource.ComposeTestCheckFunc(
+					checkGroupWrites(map[string]int{"errors": 2, "warnings": 1, "failing": 1}),
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "group_hashes.%", "2"),
+					resource.TestCheckNoResourceAttr(
+						"cortextool_rule_namespace.demo", "group_hashes.warnings"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceNamespaceParallelRequests(t *testing.T) {
+	mock := NewMockCortexRuleClient(backendLoki)
+	providerFactories := testProviderFactories(mock)
+
+	configYaml := "groups:\n"
+	for i := 0; i < 20; i++ {
+		configYaml += fmt.Sprintf(`  - name: group-%d
+    rules:
+      - record: deployment:log_messages:rate%dm
+        expr: 'sum by (deployment) (rate({deployment="grafana-agent-traces"} [%dm]))'
+`, i, i+1, i+1)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "cortextool" {
+						address = "http://localhost:8080"
+						max_parallel_requests = 4
+					}
+
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "parallel"
+						config_yaml = <<-EOT
+` + configYaml + `
+						EOT
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "group_hashes.%", "20"),
+					func(*terraform.State) error {
+						for i := 0; i < 20; i++ {
+							if writes := mock.groupWrites[fmt.Sprintf("group-%d", i)]; writes != 1 {
+								return fmt.Errorf("group-%d was written %d times, expected 1", i, writes)
+							}
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceNamespaceTimeouts(t *testing.T) {
+	providerFactories := testProviderFactories(slowGroupRuleClient{
+		MockCortexRuleClient: NewMockCortexRuleClient(backendLoki),
+		slow:                 func(rwrulefmt.RuleGroup) bool { return true },
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "cortextool" {
+						address         = "http://localhost:8080"
+						request_timeout = "1m"
+					}
+
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "grafana-agent-traces-timeouts"
+						config_yaml = file("testdata/rules2.yaml")
+
+						timeouts {
+							create = "100ms"
+						}
+					}
+					`,
+				ExpectError: regexp.MustCompile(`creating group "grafana-agent": context deadline exceeded`),
+			},
+		},
+	})
+}
+
+func TestAccResourceNamespaceTenant(t *testing.T) {
+	mock := NewMockCortexRuleClient(backendLoki)
+	providerFactories := testProviderFactories(mock)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "cortextool" {
+						address = "http://localhost:8080"
+						tenant_id = "platform"
+					}
+
+					resource "cortextool_rule_namespace" "platform" {
+						namespace = "shared"
+						config_yaml = file("testdata/rules.yaml")
+					}
+
+					resource "cortextool_rule_namespace" "team_a" {
+						namespace = "shared"
+						tenant_id = "team-a"
+						config_yaml = file("testdata/rules2.yaml")
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cortextool_rule_namespace.platform", "id", "shared"),
+					resource.TestCheckResourceAttr("cortextool_rule_namespace.team_a", "id", "team-a:shared"),
+					func(*terraform.State) error {
+						platform := ruleGroupHashes(mock.namespaces["shared"].Groups)
+						teamA := ruleGroupHashes(mock.ForTenant("team-a").(MockCortexRuleClient).namespaces["shared"].Groups)
+						if len(platform) != 1 || len(teamA) != 1 || reflect.DeepEqual(platform, teamA) {
+							return fmt.Errorf("got the groups %v for platform and %v for team-a, expected different groups", platform, teamA)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      "cortextool_rule_namespace.team_a",
+				ImportState:       true,
+				ImportStateId:     "team-a:shared",
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "cortextool_rule_namespace.platform",
+				ImportState:       true,
+				ImportStateId:     "platform/shared",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceNamespaceInvalidExpressions(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "invalid-expressions"
+						config_yaml = <<-EOT
+							groups:
+							  - name: broken
+							    rules:
+							      - alert: FirstBroken
+							        expr: 'sum(rate({deployment="app"} |= "error" [1m])'
+							      - alert: Valid
+							        expr: 'sum(rate({deployment="app"} |= "error" [1m])) > 1'
+							      - record: deployment:errors:rate1m
+							        expr: 'sum by (deployment) (rate({deployment="app"}[1m]) > '
+						EOT
+					}
+					`,
+				ExpectError: regexp.MustCompile(`(?s)group\s+"broken",\s+rule\s+"FirstBroken":\s+expr:.*group\s+"broken",\s+rule\s+"deployment:errors:rate1m":\s+expr:`),
+			},
+		},
+	})
+}
+
+func TestAccResourceNamespaceInvalidGroups(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "invalid-groups"
+						config_yaml = <<-EOT
+							groups:
+							  - name: duplicated
+							    rules:
+							      - alert: Down
+							        expr: 'sum(rate({deployment="app"} |= "error" [1m])) > 1'
+							        labels:
+							          team-name: sre
+							  - name: duplicated
+							    rules:
+							      - record: deployment:errors:rate1m
+							        expr: 'sum by (deployment) (rate({deployment="app"}[1m]))'
+							        for: 5m
+						EOT
+					}
+					`,
+				ExpectError: regexp.MustCompile(`(?s)rule\s+"Down":\s+labels:\s+"team-name"\s+is\s+not\s+a\s+valid\s+label\s+name.*group\s+"duplicated":\s+the\s+group\s+is\s+defined\s+several\s+times`),
+			},
+			{
+				Config: `
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "invalid-groups"
+
+						group {
+							name = "grafana-agent"
+							rule {
+								alert = "LogWarnMessages"
+								expr  = "sum(rate({deployment=\"grafana-agent-traces\"} |= \"level=warn\" [1m])) > 0.1"
+							}
+							rule {
+								alert = "LogWarnMessages"
+								expr  = "sum(rate({deployment=\"grafana-agent-traces\"} |= \"level=warn\" [1m])) > 1"
+							}
+						}
+					}
+					`,
+				ExpectError: regexp.MustCompile(`rule\s+"LogWarnMessages":\s+the\s+rule\s+name\s+is\s+used\s+several\s+times\s+in\s+the\s+group`),
+			},
+		},
+	})
+}
+
+func TestAccResourceNamespaceInvalidTemplates(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "invalid-templates"
+						config_yaml = <<-EOT
+							groups:
+							  - name: broken
+							    rules:
+							      - alert: LogErrorMessages
+							        expr: 'sum(rate({deployment="app"} |= "error" [1m])) > 1'
+							        annotations:
+							          summary: '{{ $labels.deployment } logs errors'
+						EOT
+					}
+					`,
+				ExpectError: regexp.MustCompile(`rule\s+"LogErrorMessages":\s+annotations:\s+template:\s+summary:1:\s+unexpected\s+"}"\s+in\s+operand`),
+			},
+			{
+				Config: `
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "invalid-templates"
+
+						group {
+							name = "broken"
+							rule {
+								alert = "LogErrorMessages"
+								expr  = "sum(rate({deployment=\"app\"} |= \"error\" [1m])) > 1"
+								annotations = {
+									summary = "{{ $labels.deployment } logs errors"
+								}
+							}
+						}
+					}
+					`,
+				ExpectError: regexp.MustCompile(`template:\s+summary:1:\s+unexpected\s+"}"\s+in\s+operand`),
+			},
+		},
+	})
+}
+
+func TestAccResourceNamespaceRuleConventions(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	config := `
+		resource "cortextool_rule_namespace" "demo" {
+			namespace = "rule-conventions"
+
+			group {
+				name = "grafana-agent"
+				rule {
+					record = "log_warn_messages"
+					expr   = "sum(rate({deployment=\"grafana-agent-traces\"} |= \"level=warn\" [1m]))"
+				}
+			}
+		}
+		`
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "group.0.rule.0.record", "log_warn_messages"),
+				),
+			},
+			{
+				Config: `
+					provider "cortextool" {
+						rule_conventions_severity = "error"
+					}
+					` + strings.Replace(config, `record = "log_warn_messages"`, `record = "deployment:log_warn_messages"`, 1),
+				ExpectError: regexp.MustCompile(`record:\s+the\s+name\s+does\s+not\s+follow\s+the\s+level:metric:operations\s+convention`),
+			},
+		},
+	})
+}
+
+func TestAccResourceNamespacePolicy(t *testing.T) {
+	os.Setenv("CORTEXTOOL_ADDRESS", "http://localhost:8080")
+	defer os.Unsetenv("CORTEXTOOL_ADDRESS")
+
+	provider := `
+		provider "cortextool" {
+			policy {
+				required_labels         = ["team", "severity"]
+				required_annotations    = ["runbook_url"]
+				allowed_severities      = ["critical", "warning"]
+				forbidden_expr_patterns = ["=~\"\\.\\*\""]
+			}
+		}
+		`
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + `
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "policy"
+						config_yaml = file("testdata/rules2.yaml")
+					}
+					`,
+				ExpectError: regexp.MustCompile(`(?s)rule\s+"LogWarnMessages":\s+labels:\s+"severity"\s+is\s+required\s+by\s+the\s+policy.*annotations:\s+"runbook_url"\s+is\s+required`),
+			},
+			{
+				Config: provider + `
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "policy"
+
+						group {
+							name = "grafana-agent"
+							rule {
+								alert = "LogWarnMessages"
+								expr  = "sum(rate({deployment=\"grafana-agent-traces\", pod=~\".*\"} |= \"level=warn\" [1m])) > 0.1"
+								labels = {
+									team     = "sre"
+									severity = "warning"
+								}
+								annotations = {
+									runbook_url = "https://example.com/runbooks/log-warn-messages"
+								}
+							}
+						}
+					}
+					`,
+				ExpectError: regexp.MustCompile(`expr:\s+matches\s+the\s+pattern\s+.*\s+forbidden\s+by\s+the\s+policy`),
+			},
+			{
+				Config: provider + `
+					resource "cortextool_rule_namespace" "demo" {
+						namespace = "policy"
+
+						group {
+							name = "grafana-agent"
+							rule {
+								alert = "LogWarnMessages"
+								expr  = "sum(rate({deployment=\"grafana-agent-traces\"} |= \"level=warn\" [1m])) > 0.1"
+								labels = {
+									team     = "sre"
+									severity = "warning"
+								}
+								annotations = {
+									runbook_url = "https://example.com/runbooks/log-warn-messages"
+								}
+							}
+						}
+					}
+					`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cortextool_rule_namespace.demo", "group.0.rule.0.labels.severity", "warning"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,239 @@
+package cortextool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/prometheus/common/model"
+	"golang.org/x/exp/slices"
+)
+
+// defaultRetryStatusCodes are the HTTP status codes retried when retry_status_codes is not set.
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+type retryConfig struct {
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	statusCodes []int
+}
+
+func getRetryConfig(d *schema.ResourceData) (retryConfig, error) {
+	config := retryConfig{
+		maxAttempts: d.Get("retry_max_attempts").(int),
+		statusCodes: defaultRetryStatusCodes,
+	}
+
+	backoffBase, err := model.ParseDuration(d.Get("retry_backoff_base").(string))
+	if err != nil {
+		return config, fmt.Errorf("retry_backoff_base: %w", err)
+	}
+	config.backoffBase = time.Duration(backoffBase)
+
+	backoffMax, err := model.ParseDuration(d.Get("retry_backoff_max").(string))
+	if err != nil {
+		return config, fmt.Errorf("retry_backoff_max: %w", err)
+	}
+	config.backoffMax = time.Duration(backoffMax)
+
+	if rawStatusCodes := d.Get("retry_status_codes").([]any); len(rawStatusCodes) > 0 {
+		config.statusCodes = make([]int, 0, len(rawStatusCodes))
+		for _, statusCode := range rawStatusCodes {
+			config.statusCodes = append(config.statusCodes, statusCode.(int))
+		}
+	}
+	return config, nil
+}
+
+// backoff returns the delay before the attempt following the given one, doubling from the
+// base delay up to the maximum one. A longer Retry-After sent by the ruler takes precedence, within
+// the maximum delay too.
+func (c retryConfig) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	delay := c.backoffBase
+	for i := 1; i < attempt && delay < c.backoffMax; i++ {
+		delay *= 2
+	}
+	if delay > c.backoffMax {
+		delay = c.backoffMax
+	}
+	if retryAfter > delay {
+		delay = min(retryAfter, c.backoffMax)
+	}
+	return delay
+}
+
+// httpStatusError is returned by statusErrorTransport for the responses with a retryable status.
+type httpStatusError struct {
+	StatusCode int
+	Status     string
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("server returned HTTP status: %s, body: %q", e.Status, e.Body)
+}
+
+// statusErrorTransport turns the responses with a retryable status into an httpStatusError, so that
+// their status and Retry-After header reach the retrying clients through the cortextool client's errors.
+type statusErrorTransport struct {
+	next        http.RoundTripper
+	statusCodes []int
+}
+
+func (t *statusErrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || !slices.Contains(t.statusCodes, resp.StatusCode) {
+		return resp, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, &httpStatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       string(body),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter parses a Retry-After header holding either a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// isRetryableError returns whether the error is transient, along with the delay requested by the ruler.
+func isRetryableError(err error) (bool, time.Duration) {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return true, statusErr.RetryAfter
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true, 0
+	}
+	return false, 0
+}
+
+// retryingRuleClient retries the calls of a CortexRuleClient failing with a transient error.
+type retryingRuleClient struct {
+	client CortexRuleClient
+	config retryConfig
+}
+
+func newRetryingRuleClient(client CortexRuleClient, config retryConfig) *retryingRuleClient {
+	return &retryingRuleClient{
+		client: client,
+		config: config,
+	}
+}
+
+// retry calls fn until it succeeds, fails with a permanent error or runs out of attempts.
+func (c retryConfig) retry(ctx context.Context, operation string, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= c.maxAttempts {
+			return err
+		}
+		retryable, retryAfter := isRetryableError(err)
+		if !retryable {
+			return err
+		}
+
+		delay := c.backoff(attempt, retryAfter)
+		tflog.Warn(ctx, "Retrying request after a transient error", map[string]any{
+			"operation": operation,
+			"attempt":   attempt,
+			"delay":     delay.String(),
+			"error":     err.Error(),
+		})
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (r *retryingRuleClient) CreateRuleGroup(ctx context.Context, namespace string, group rwrulefmt.RuleGroup) error {
+	return r.config.retry(ctx, "CreateRuleGroup", func() error {
+		return r.client.CreateRuleGroup(ctx, namespace, group)
+	})
+}
+
+func (r *retryingRuleClient) DeleteRuleGroup(ctx context.Context, namespace string, groupName string) error {
+	return r.config.retry(ctx, "DeleteRuleGroup", func() error {
+		return r.client.DeleteRuleGroup(ctx, namespace, groupName)
+	})
+}
+
+func (r *retryingRuleClient) ListRules(ctx context.Context, namespace string) (map[string][]rwrulefmt.RuleGroup, error) {
+	var ruleGroups map[string][]rwrulefmt.RuleGroup
+	err := r.config.retry(ctx, "ListRules", func() error {
+		var err error
+		ruleGroups, err = r.client.ListRules(ctx, namespace)
+		return err
+	})
+	return ruleGroups, err
+}
+
+// retryingAlertmanagerClient retries the calls of a CortexAlertmanagerClient failing with a transient error.
+type retryingAlertmanagerClient struct {
+	client CortexAlertmanagerClient
+	config retryConfig
+}
+
+func newRetryingAlertmanagerClient(client CortexAlertmanagerClient, config retryConfig) *retryingAlertmanagerClient {
+	return &retryingAlertmanagerClient{
+		client: client,
+		config: config,
+	}
+}
+
+func (r *retryingAlertmanagerClient) CreateAlertmanagerConfig(ctx context.Context, config string, templates map[string]string) error {
+	return r.config.retry(ctx, "CreateAlertmanagerConfig", func() error {
+		return r.client.CreateAlertmanagerConfig(ctx, config, templates)
+	})
+}
+
+func (r *retryingAlertmanagerClient) DeleteAlermanagerConfig(ctx context.Context) error {
+	return r.config.retry(ctx, "DeleteAlertmanagerConfig", func() error {
+		return r.client.DeleteAlermanagerConfig(ctx)
+	})
+}
+
+func (r *retryingAlertmanagerClient) GetAlertmanagerConfig(ctx context.Context) (string, map[string]string, error) {
+	var (
+		config    string
+		templates map[string]string
+	)
+	err := r.config.retry(ctx, "GetAlertmanagerConfig", func() error {
+		var err error
+		config, templates, err = r.client.GetAlertmanagerConfig(ctx)
+		return err
+	})
+	return config, templates, err
+}
@@ -0,0 +1,159 @@
+package cortextool
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
+	"github.com/prometheus/prometheus/model/rulefmt"
+)
+
+// flakyRuleClient fails the group creations with the given errors before delegating to the mock.
+type flakyRuleClient struct {
+	MockCortexRuleClient
+	errors []error
+	calls  int
+}
+
+func (f *flakyRuleClient) CreateRuleGroup(ctx context.Context, namespace string, group rwrulefmt.RuleGroup) error {
+	f.calls++
+	if len(f.errors) > 0 {
+		err := f.errors[0]
+		f.errors = f.errors[1:]
+		return err
+	}
+	return f.MockCortexRuleClient.CreateRuleGroup(ctx, namespace, group)
+}
+
+func TestRetryingRuleClient(t *testing.T) {
+	unavailable := &httpStatusError{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}
+	config := retryConfig{maxAttempts: 3, backoffBase: time.Millisecond, backoffMax: 10 * time.Millisecond}
+	group := rwrulefmt.RuleGroup{RuleGroup: rulefmt.RuleGroup{Name: "sre"}}
+
+	tests := []struct {
+		name          string
+		errors        []error
+		expectedCalls int
+		expectedError bool
+	}{
+		{name: "success", expectedCalls: 1},
+		{name: "transient errors", errors: []error{unavailable, unavailable}, expectedCalls: 3},
+		{name: "too many transient errors", errors: []error{unavailable, unavailable, unavailable}, expectedCalls: 3, expectedError: true},
+		{name: "permanent error", errors: []error{errors.New("bad request")}, expectedCalls: 1, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flaky := &flakyRuleClient{MockCortexRuleClient: NewMockCortexRuleClient(backendLoki), errors: tt.errors}
+			client := newRetryingRuleClient(flaky, config)
+
+			err := client.CreateRuleGroup(context.Background(), "shared", group)
+			if (err != nil) != tt.expectedError {
+				t.Errorf("unexpected error %v", err)
+			}
+			if flaky.calls != tt.expectedCalls {
+				t.Errorf("got %d calls, expected %d", flaky.calls, tt.expectedCalls)
+			}
+		})
+	}
+}
+
+// flakyAlertmanagerClient fails the configuration reads with the given errors before delegating to the mock.
+type flakyAlertmanagerClient struct {
+	MockCortexRuleClient
+	errors []error
+	calls  int
+}
+
+func (f *flakyAlertmanagerClient) GetAlertmanagerConfig(ctx context.Context) (string, map[string]string, error) {
+	f.calls++
+	if len(f.errors) > 0 {
+		err := f.errors[0]
+		f.errors = f.errors[1:]
+		return "", nil, err
+	}
+	return f.MockCortexRuleClient.GetAlertmanagerConfig(ctx)
+}
+
+func TestRetryingAlertmanagerClient(t *testing.T) {
+	unavailable := &httpStatusError{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}
+	config := retryConfig{maxAttempts: 3, backoffBase: time.Millisecond, backoffMax: 10 * time.Millisecond}
+
+	flaky := &flakyAlertmanagerClient{MockCortexRuleClient: NewMockCortexRuleClient(backendLoki), errors: []error{unavailable, unavailable}}
+	if err := flaky.CreateAlertmanagerConfig(context.Background(), "route: {}", nil); err != nil {
+		t.Fatal(err)
+	}
+	client := newRetryingAlertmanagerClient(flaky, config)
+
+	amConfig, _, err := client.GetAlertmanagerConfig(context.Background())
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if amConfig != "route: {}" {
+		t.Errorf("got config %q, expected the mock's one", amConfig)
+	}
+	if flaky.calls != 3 {
+		t.Errorf("got %d calls, expected 3", flaky.calls)
+	}
+}
+
+func TestRetryConfigBackoff(t *testing.T) {
+	config := retryConfig{backoffBase: time.Second, backoffMax: 5 * time.Second}
+
+	tests := []struct {
+		attempt    int
+		retryAfter time.Duration
+		expected   time.Duration
+	}{
+		{attempt: 1, expected: time.Second},
+		{attempt: 2, expected: 2 * time.Second},
+		{attempt: 3, expected: 4 * time.Second},
+		{attempt: 4, expected: 5 * time.Second},
+		{attempt: 1, retryAfter: 3 * time.Second, expected: 3 * time.Second},
+		{attempt: 3, retryAfter: 3 * time.Second, expected: 4 * time.Second},
+		{attempt: 1, retryAfter: time.Hour, expected: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if delay := config.backoff(tt.attempt, tt.retryAfter); delay != tt.expected {
+			t.Errorf("attempt %d with Retry-After %s: got %s, expected %s", tt.attempt, tt.retryAfter, delay, tt.expected)
+		}
+	}
+}
+
+func TestStatusErrorTransport(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := http.Client{
+		Transport: &statusErrorTransport{next: http.DefaultTransport, statusCodes: defaultRetryStatusCodes},
+	}
+
+	_, err := client.Get(server.URL)
+	retryable, retryAfter := isRetryableError(err)
+	if !retryable || retryAfter != 2*time.Second {
+		t.Errorf("got retryable %t with Retry-After %s for %v", retryable, retryAfter, err)
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,151 @@
+package cortextool
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	// rulerAPIPathCortex is the route the cortextool client sends requests to when not using legacy routes.
+	rulerAPIPathCortex = "/api/v1/rules"
+	// rulerAPIPathLegacy is the route the cortextool client sends requests to when using legacy routes.
+	rulerAPIPathLegacy = "/api/prom/rules"
+	rulerAPIPathLoki   = "/loki/api/v1/rules"
+	rulerAPIPathMimir  = "/prometheus/config/v1/rules"
+
+	// rulerAPIPathAuto probes the ruler for the route it answers.
+	rulerAPIPathAuto = "auto"
+)
+
+// defaultRulerAPIPath returns the route of the ruler API exposed by the backend.
+func defaultRulerAPIPath(backend string) string {
+	switch backend {
+	case backendCortex:
+		return rulerAPIPathCortex
+	case backendMimir:
+		return rulerAPIPathMimir
+	default:
+		return rulerAPIPathLoki
+	}
+}
+
+// useLegacyRoutes returns whether to send the requests to the legacy routes: use_legacy_routes when
+// set, otherwise the loki backend keeps the legacy routes it always used unless ruler_api_path is set.
+func useLegacyRoutes(d *schema.ResourceData) bool {
+	config := d.GetRawConfig()
+	if (!config.IsNull() && !config.GetAttr("use_legacy_routes").IsNull()) ||
+		os.Getenv("CORTEXTOOL_USE_LEGACY_ROUTES") != "" {
+		return d.Get("use_legacy_routes").(bool)
+	}
+	return d.Get("backend").(string) == backendLoki && d.Get("ruler_api_path").(string) == ""
+}
+
+// probedRulerAPIPaths returns the routes to probe for the backend, the preferred ones first.
+func probedRulerAPIPaths(backend string) []string {
+	paths := []string{defaultRulerAPIPath(backend)}
+	for _, path := range []string{rulerAPIPathLoki, rulerAPIPathMimir, rulerAPIPathCortex, rulerAPIPathLegacy} {
+		if path != paths[0] {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// rulerPathTransport sends the requests the cortextool client makes to its own ruler route to apiPath instead.
+type rulerPathTransport struct {
+	next http.RoundTripper
+	// from and to are the routes prefixed with the path of the provider's address
+	from string
+	to   string
+}
+
+func newRulerPathTransport(next http.RoundTripper, address string, apiPath string) (*rulerPathTransport, error) {
+	endpoint, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+	basePath := strings.TrimSuffix(endpoint.Path, "/")
+	return &rulerPathTransport{
+		next: next,
+		from: basePath + rulerAPIPathCortex,
+		to:   basePath + apiPath,
+	}, nil
+}
+
+func (t *rulerPathTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasPrefix(req.URL.Path, t.from) {
+		return t.next.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.URL.Path = t.to + strings.TrimPrefix(req.URL.Path, t.from)
+	if req.URL.RawPath != "" {
+		req.URL.RawPath = t.to + strings.TrimPrefix(req.URL.RawPath, t.from)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// rulerProbe sends requests authenticated like the cortextool client's ones.
+type rulerProbe struct {
+	client   *http.Client
+	address  string
+	user     string
+	key      string
+	tenantID string
+}
+
+// answers returns whether the ruler serves the route: a missing route yields a 404 or 405,
+// while a route without any rule yields a 404 with a dedicated message. The rejected
+// credentials fail the probe as they tell nothing about the route.
+func (p rulerProbe) answers(ctx context.Context, apiPath string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.address, "/")+apiPath, nil)
+	if err != nil {
+		return false, err
+	}
+	if p.user != "" {
+		req.SetBasicAuth(p.user, p.key)
+	} else if p.key != "" {
+		req.SetBasicAuth(p.tenantID, p.key)
+	}
+	req.Header.Set("X-Scope-OrgID", p.tenantID)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return strings.Contains(string(body), "no rule groups found"), nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, fmt.Errorf("the ruler rejected the credentials: %s", resp.Status)
+	default:
+		return resp.StatusCode >= 200 && resp.StatusCode < 300, nil
+	}
+}
+
+// detectRulerAPIPath returns the first of the backend's routes the ruler answers.
+func (p rulerProbe) detectRulerAPIPath(ctx context.Context, backend string) (string, error) {
+	for _, apiPath := range probedRulerAPIPaths(backend) {
+		ok, err := p.answers(ctx, apiPath)
+		if err != nil {
+			return "", fmt.Errorf("probing the ruler API at %s: %w", apiPath, err)
+		}
+		if ok {
+			tflog.Info(ctx, "Detected the ruler API route", map[string]any{"path": apiPath})
+			return apiPath, nil
+		}
+	}
+	return "", fmt.Errorf("the ruler at %s does not answer any of the routes %s",
+		p.address, strings.Join(probedRulerAPIPaths(backend), ", "))
+}
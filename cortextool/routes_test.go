@@ -0,0 +1,112 @@
+package cortextool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestRulerPathTransport(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+	}))
+	defer server.Close()
+
+	transport, err := newRulerPathTransport(http.DefaultTransport, server.URL+"/prefix/", rulerAPIPathMimir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := http.Client{Transport: transport}
+
+	for _, path := range []string{"/prefix/api/v1/rules/team%2Fsre/alerts", "/prefix/api/v1/alerts"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	expected := []string{"/prefix/prometheus/config/v1/rules/team%2Fsre/alerts", "/prefix/api/v1/alerts"}
+	for i, path := range expected {
+		if paths[i] != path {
+			t.Errorf("got path %s, expected %s", paths[i], path)
+		}
+	}
+}
+
+func TestDetectRulerAPIPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Scope-OrgID") != "sre" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		if r.URL.Path == rulerAPIPathMimir {
+			w.Write([]byte("no rule groups found"))
+		} else {
+			w.Write([]byte("404 page not found"))
+		}
+	}))
+	defer server.Close()
+
+	probe := rulerProbe{client: server.Client(), address: server.URL, tenantID: "sre"}
+	apiPath, err := probe.detectRulerAPIPath(context.Background(), backendLoki)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if apiPath != rulerAPIPathMimir {
+		t.Errorf("detected %s, expected %s", apiPath, rulerAPIPathMimir)
+	}
+
+	probe.address = server.URL + "/missing"
+	if _, err := probe.detectRulerAPIPath(context.Background(), backendLoki); err == nil {
+		t.Error("expected an error when no route answers")
+	}
+}
+
+func TestDetectRulerAPIPathRejectedCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	probe := rulerProbe{client: server.Client(), address: server.URL, tenantID: "sre"}
+	_, err := probe.detectRulerAPIPath(context.Background(), backendLoki)
+	if err == nil || !strings.Contains(err.Error(), "rejected the credentials") {
+		t.Errorf("got %v, expected the rejected credentials to fail the probe", err)
+	}
+}
+
+func TestUseLegacyRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      map[string]any
+		env      string
+		expected bool
+	}{
+		{name: "loki", raw: map[string]any{"backend": backendLoki}, expected: true},
+		{name: "loki with a ruler API path", raw: map[string]any{"backend": backendLoki, "ruler_api_path": rulerAPIPathLoki}},
+		{name: "loki with the environment", raw: map[string]any{"backend": backendLoki}, env: "false"},
+		{name: "mimir", raw: map[string]any{"backend": backendMimir}},
+		{name: "mimir with the environment", raw: map[string]any{"backend": backendMimir}, env: "true", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				os.Setenv("CORTEXTOOL_USE_LEGACY_ROUTES", tt.env)
+				defer os.Unsetenv("CORTEXTOOL_USE_LEGACY_ROUTES")
+			}
+			d := schema.TestResourceDataRaw(t, New("dev", nil)().Schema, tt.raw)
+			if got := useLegacyRoutes(d); got != tt.expected {
+				t.Errorf("got %t, expected %t", got, tt.expected)
+			}
+		})
+	}
+}
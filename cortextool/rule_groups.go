@@ -0,0 +1,221 @@
+package cortextool
+
+import (
+	"fmt"
+
+	"github.com/grafana/cortex-tools/pkg/rules"
+	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/rulefmt"
+)
+
+// ruleGroupSchema is the HCL equivalent of a rwrulefmt.RuleGroup.
+func ruleGroupSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Description: "The name of the group",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"interval": {
+				Description:      "How often the group's rules are evaluated, the ruler's default is used when not set",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validateDuration,
+				DiffSuppressFunc: diffDuration,
+			},
+			"limit": {
+				Description:  "Limit the number of alerts an alerting rule and series a recording rule can produce, 0 is no limit",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"rule": {
+				Description: "The group's rules",
+				Type:        schema.TypeList,
+				Required:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"alert": {
+							Description: "The name of the alert, exactly one of `alert` or `record` must be set",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"record": {
+							Description:      "The name of the series to record, exactly one of `alert` or `record` must be set",
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateDiagFunc: validateRecordName,
+						},
+						"expr": {
+							Description:      "The expression to evaluate",
+							Type:             schema.TypeString,
+							Required:         true,
+							DiffSuppressFunc: diffExpression,
+						},
+						"for": {
+							Description:      "How long the alert condition must hold before firing",
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validateDuration,
+							DiffSuppressFunc: diffDuration,
+						},
+						"labels": {
+							Description:      "The labels to add or overwrite",
+							Type:             schema.TypeMap,
+							Elem:             &schema.Schema{Type: schema.TypeString},
+							Optional:         true,
+							ValidateDiagFunc: validateLabelNames,
+						},
+						"annotations": {
+							Description:      "The annotations to add to the alerts",
+							Type:             schema.TypeMap,
+							Elem:             &schema.Schema{Type: schema.TypeString},
+							Optional:         true,
+							ValidateDiagFunc: validateAnnotations,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func expandRuleGroups(rawGroups []any) ([]rwrulefmt.RuleGroup, error) {
+	groups := make([]rwrulefmt.RuleGroup, 0, len(rawGroups))
+	for _, rawGroup := range rawGroups {
+		g := rawGroup.(map[string]any)
+		group := rwrulefmt.RuleGroup{
+			RuleGroup: rulefmt.RuleGroup{
+				Name:  g["name"].(string),
+				Limit: g["limit"].(int),
+			},
+		}
+
+		var err error
+		if group.Interval, err = parseOptionalDuration(g["interval"].(string)); err != nil {
+			return nil, fmt.Errorf("group %q: %w", group.Name, err)
+		}
+
+		for i, rawRule := range g["rule"].([]any) {
+			r := rawRule.(map[string]any)
+			alert, record := r["alert"].(string), r["record"].(string)
+			if (alert == "") == (record == "") {
+				return nil, fmt.Errorf("group %q: rule #%d: exactly one of alert or record must be set", group.Name, i+1)
+			}
+
+			var rule rulefmt.RuleNode
+			if alert != "" {
+				rule.Alert.SetString(alert)
+			} else {
+				rule.Record.SetString(record)
+			}
+			rule.Expr.SetString(r["expr"].(string))
+			if rule.For, err = parseOptionalDuration(r["for"].(string)); err != nil {
+				return nil, fmt.Errorf("group %q: rule #%d: %w", group.Name, i+1, err)
+			}
+			if labels := stringValueMap(r["labels"].(map[string]any)); len(labels) > 0 {
+				rule.Labels = labels
+			}
+			if annotations := stringValueMap(r["annotations"].(map[string]any)); len(annotations) > 0 {
+				rule.Annotations = annotations
+			}
+			group.Rules = append(group.Rules, rule)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func flattenRuleGroups(groups []rwrulefmt.RuleGroup) []any {
+	flattened := make([]any, 0, len(groups))
+	for _, group := range groups {
+		groupRules := make([]any, 0, len(group.Rules))
+		for _, rule := range group.Rules {
+			groupRules = append(groupRules, map[string]any{
+				"alert":       rule.Alert.Value,
+				"record":      rule.Record.Value,
+				"expr":        rule.Expr.Value,
+				"for":         formatOptionalDuration(rule.For),
+				"labels":      rule.Labels,
+				"annotations": rule.Annotations,
+			})
+		}
+
+		flattened = append(flattened, map[string]any{
+			"name":     group.Name,
+			"interval": formatOptionalDuration(group.Interval),
+			"limit":    group.Limit,
+			"rule":     groupRules,
+		})
+	}
+	return flattened
+}
+
+func ruleGroupNames(groups []rwrulefmt.RuleGroup) []string {
+	names := make([]string, 0, len(groups))
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+	return names
+}
+
+func parseOptionalDuration(s string) (model.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return model.ParseDuration(s)
+}
+
+func formatOptionalDuration(d model.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+func validateDuration(i any, k string) ([]string, []error) {
+	if _, err := parseOptionalDuration(i.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%q: %w", k, err)}
+	}
+	return nil, nil
+}
+
+// lintExpression returns the expression as formatted by the backend's parser.
+func lintExpression(expr string, backend string) (string, error) {
+	var rule rulefmt.RuleNode
+	rule.Expr.SetString(expr)
+	namespace := rules.RuleNamespace{
+		Groups: []rwrulefmt.RuleGroup{{RuleGroup: rulefmt.RuleGroup{Rules: []rulefmt.RuleNode{rule}}}},
+	}
+	_, _, err := namespace.LintExpressions(lintBackend(backend))
+	return namespace.Groups[0].Rules[0].Expr.Value, err
+}
+
+func diffExpression(k, oldValue, newValue string, d *schema.ResourceData) bool {
+	backend := d.Get("backend").(string)
+	oldExpr, err := lintExpression(oldValue, backend)
+	if err != nil {
+		return false
+	}
+	newExpr, err := lintExpression(newValue, backend)
+	if err != nil {
+		return false
+	}
+	return oldExpr == newExpr
+}
+
+func diffDuration(k, oldValue, newValue string, d *schema.ResourceData) bool {
+	oldDuration, err := parseOptionalDuration(oldValue)
+	if err != nil {
+		return false
+	}
+	newDuration, err := parseOptionalDuration(newValue)
+	if err != nil {
+		return false
+	}
+	return oldDuration == newDuration
+}
@@ -0,0 +1,148 @@
+package cortextool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	cortextool "github.com/grafana/cortex-tools/pkg/client"
+	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// rollbackTimeout bounds the rollback, which does not share the deadline of the failed operation.
+const rollbackTimeout = 5 * time.Minute
+
+// ruleNamespaceTransaction records the groups changed in a namespace so that they can be
+// restored to a snapshot of the namespace taken before any change.
+type ruleNamespaceTransaction struct {
+	client      CortexRuleClient
+	namespace   string
+	parallelism int
+	previous    map[string]rwrulefmt.RuleGroup
+
+	mu      sync.Mutex
+	changed []string
+}
+
+func newRuleNamespaceTransaction(client CortexRuleClient, namespace string, parallelism int,
+	snapshot []rwrulefmt.RuleGroup) *ruleNamespaceTransaction {
+	previous := make(map[string]rwrulefmt.RuleGroup, len(snapshot))
+	for _, group := range snapshot {
+		previous[group.Name] = group
+	}
+	return &ruleNamespaceTransaction{
+		client:      client,
+		namespace:   namespace,
+		parallelism: parallelism,
+		previous:    previous,
+	}
+}
+
+func (t *ruleNamespaceTransaction) recordChange(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.changed = append(t.changed, name)
+}
+
+// createRuleGroup creates or replaces the group. The group is recorded before the call
+// as a failed request may still have been applied by the ruler.
+func (t *ruleNamespaceTransaction) createRuleGroup(ctx context.Context, group rwrulefmt.RuleGroup) error {
+	t.recordChange(group.Name)
+	if err := t.client.CreateRuleGroup(ctx, t.namespace, group); err != nil {
+		return fmt.Errorf("creating group %q: %w", group.Name, err)
+	}
+	return nil
+}
+
+func (t *ruleNamespaceTransaction) deleteRuleGroup(ctx context.Context, name string) error {
+	t.recordChange(name)
+	err := t.client.DeleteRuleGroup(ctx, t.namespace, name)
+	if err != nil && !errors.Is(err, cortextool.ErrResourceNotFound) {
+		return fmt.Errorf("deleting group %q: %w", name, err)
+	}
+	return nil
+}
+
+// apply creates or replaces the created groups then deletes the deleted ones, fanning the requests
+// out with the transaction's parallelism. No new request is sent once one failed.
+func (t *ruleNamespaceTransaction) apply(ctx context.Context, created []rwrulefmt.RuleGroup, deleted []string) []error {
+	return forEachParallel(t.parallelism, len(created)+len(deleted), true, func(i int) error {
+		if i < len(created) {
+			return t.createRuleGroup(ctx, created[i])
+		}
+		return t.deleteRuleGroup(ctx, deleted[i-len(created)])
+	})
+}
+
+// rollback restores the changed groups, in reverse order when not parallel, and returns the names
+// of the restored ones. It runs even when ctx is done, as when the operation hit its timeout.
+func (t *ruleNamespaceTransaction) rollback(ctx context.Context) ([]string, []error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+
+	t.mu.Lock()
+	changed := make([]string, 0, len(t.changed))
+	for i := len(t.changed) - 1; i >= 0; i-- {
+		changed = append(changed, t.changed[i])
+	}
+	t.mu.Unlock()
+
+	restored := make([]bool, len(changed))
+	errs := forEachParallel(t.parallelism, len(changed), false, func(i int) error {
+		name := changed[i]
+		var err error
+		if group, ok := t.previous[name]; ok {
+			err = t.client.CreateRuleGroup(ctx, t.namespace, group)
+		} else {
+			err = t.client.DeleteRuleGroup(ctx, t.namespace, name)
+			if errors.Is(err, cortextool.ErrResourceNotFound) {
+				err = nil
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("restoring group %q: %w", name, err)
+		}
+		restored[i] = true
+		return nil
+	})
+
+	var restoredNames []string
+	for i, name := range changed {
+		if restored[i] {
+			restoredNames = append(restoredNames, name)
+		}
+	}
+	return restoredNames, errs
+}
+
+// rollbackDiagnostics rolls the transaction back after errs and reports the restored groups,
+// with a diagnostic for each group which failed.
+func (t *ruleNamespaceTransaction) rollbackDiagnostics(ctx context.Context, errs []error) diag.Diagnostics {
+	restored, rollbackErrs := t.rollback(ctx)
+	tflog.Warn(ctx, "Restored rule groups after a failed update", map[string]any{
+		"namespace": t.namespace,
+		"restored":  restored,
+	})
+
+	var diags diag.Diagnostics
+	for _, err := range errs {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Failed to apply namespace %q, restored groups: %s", t.namespace, strings.Join(restored, ", ")),
+			Detail:   err.Error(),
+		})
+	}
+	for _, err := range rollbackErrs {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Failed to restore namespace %q, the ruler holds a mix of the previous and new groups", t.namespace),
+			Detail:   err.Error(),
+		})
+	}
+	return diags
+}
@@ -0,0 +1,85 @@
+package cortextool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"golang.org/x/exp/slices"
+)
+
+// slowGroupRuleClient never creates the slow groups before the context is done, and like the real
+// client fails the requests sent with a done context.
+type slowGroupRuleClient struct {
+	MockCortexRuleClient
+	slow func(rwrulefmt.RuleGroup) bool
+}
+
+func (c slowGroupRuleClient) CreateRuleGroup(ctx context.Context, namespace string, group rwrulefmt.RuleGroup) error {
+	if c.slow(group) {
+		<-ctx.Done()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.MockCortexRuleClient.CreateRuleGroup(ctx, namespace, group)
+}
+
+func (c slowGroupRuleClient) DeleteRuleGroup(ctx context.Context, namespace, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.MockCortexRuleClient.DeleteRuleGroup(ctx, namespace, name)
+}
+
+func TestRuleNamespaceTransactionRollbackAfterDeadline(t *testing.T) {
+	testGroup := func(name string, interval time.Duration) rwrulefmt.RuleGroup {
+		return rwrulefmt.RuleGroup{RuleGroup: rulefmt.RuleGroup{
+			Name:     name,
+			Interval: model.Duration(interval),
+			Rules:    []rulefmt.RuleNode{testAlertRule("Errors", `sum(rate({job="app"} |= "error" [1m])) > 1`)},
+		}}
+	}
+	// Only the update of the slow group hangs, restoring it succeeds
+	client := slowGroupRuleClient{
+		MockCortexRuleClient: NewMockCortexRuleClient(backendLoki),
+		slow: func(group rwrulefmt.RuleGroup) bool {
+			return group.Name == "slow" && group.Interval != model.Duration(time.Minute)
+		},
+	}
+	snapshot := []rwrulefmt.RuleGroup{testGroup("fast", time.Minute), testGroup("slow", time.Minute)}
+	for _, group := range snapshot {
+		if err := client.CreateRuleGroup(context.Background(), "ns", group); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	tx := newRuleNamespaceTransaction(client, "ns", 1, snapshot)
+	errs := tx.apply(ctx, []rwrulefmt.RuleGroup{testGroup("fast", 2*time.Minute), testGroup("slow", 2*time.Minute)}, nil)
+	if len(errs) != 1 || !errors.Is(errs[0], context.DeadlineExceeded) {
+		t.Fatalf("got %v, expected the slow group to exceed the deadline", errs)
+	}
+
+	restored, rollbackErrs := tx.rollback(ctx)
+	if len(rollbackErrs) > 0 {
+		t.Fatalf("the rollback failed: %v", rollbackErrs)
+	}
+	if expected := []string{"slow", "fast"}; !slices.Equal(restored, expected) {
+		t.Errorf("got restored groups %v, expected %v", restored, expected)
+	}
+	remote, err := client.ListRules(context.Background(), "ns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, group := range remote["ns"] {
+		if group.Interval != model.Duration(time.Minute) {
+			t.Errorf("got interval %s for group %q, expected the previous 1m", group.Interval, group.Name)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package cortextool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/cortex-tools/pkg/rules"
+	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
+	"github.com/grafana/loki/pkg/logql/syntax"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/template"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	ruleSeverityWarning = "warning"
+	ruleSeverityError   = "error"
+)
+
+var ruleSeverities = []string{ruleSeverityWarning, ruleSeverityError}
+
+// recordingRuleNameRegexp matches the level:metric:operations naming convention of the recording rules.
+var recordingRuleNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*:[a-zA-Z_][a-zA-Z0-9_]*:[a-zA-Z0-9_]+$`)
+
+// templateDefinitions are the variables the ruler defines before expanding the labels and annotations
+// of an alert.
+const templateDefinitions = "{{$labels := .Labels}}{{$externalLabels := .ExternalLabels}}" +
+	"{{$externalURL := .ExternalURL}}{{$value := .Value}}"
+
+// ruleIssue is a problem found in a rule namespace, located down to the group and rule.
+type ruleIssue struct {
+	namespace string
+	group     string
+	// rule is the name of the rule, empty for the issues of the group itself
+	rule string
+	err  error
+}
+
+func (i ruleIssue) Error() string {
+	var location []string
+	if i.namespace != "" {
+		location = append(location, fmt.Sprintf("namespace %q", i.namespace))
+	}
+	if i.group != "" {
+		location = append(location, fmt.Sprintf("group %q", i.group))
+	}
+	if i.rule != "" {
+		location = append(location, fmt.Sprintf("rule %q", i.rule))
+	}
+	if len(location) == 0 {
+		return i.err.Error()
+	}
+	return strings.Join(location, ", ") + ": " + i.err.Error()
+}
+
+func (i ruleIssue) Unwrap() error {
+	return i.err
+}
+
+// ruleName returns the alert or record of the rule, or its position when it has neither.
+func ruleName(rule rulefmt.RuleNode, i int) string {
+	if rule.Alert.Value != "" {
+		return rule.Alert.Value
+	}
+	if rule.Record.Value != "" {
+		return rule.Record.Value
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+// newRuleIssue locates err at the i-th rule of the group.
+func newRuleIssue(namespace string, group rwrulefmt.RuleGroup, i int, err error) ruleIssue {
+	return ruleIssue{
+		namespace: namespace,
+		group:     group.Name,
+		rule:      ruleName(group.Rules[i], i),
+		err:       err,
+	}
+}
+
+// joinRuleIssues returns an error listing the issues, one per line, or nil when there are none.
+func joinRuleIssues(issues []ruleIssue) error {
+	errs := make([]error, 0, len(issues))
+	for _, issue := range issues {
+		errs = append(errs, issue)
+	}
+	return errors.Join(errs...)
+}
+
+// ruleIssuesDiagnostics returns a diagnostic of the given severity for each issue.
+func ruleIssuesDiagnostics(issues []ruleIssue, severity diag.Severity, path cty.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, issue := range issues {
+		summary := "Rule definition is not valid."
+		if severity == diag.Warning {
+			summary = "Rule definition does not follow the conventions."
+		}
+		diags = append(diags, diag.Diagnostic{
+			Severity:      severity,
+			Summary:       summary,
+			Detail:        issue.Error(),
+			AttributePath: path,
+		})
+	}
+	return diags
+}
+
+// checkRuleNamespace returns the issues failing the plan: the invalid definitions and expressions,
+// the departures from the conventions when the provider treats them as errors and the policy breaches.
+func checkRuleNamespace(ruleNamespace rules.RuleNamespace, backend string, meta any) []ruleIssue {
+	providerData := meta.(*providerData)
+	issues := checkRuleGroups(ruleNamespace)
+	issues = append(issues, checkRuleExpressions(ruleNamespace, backend)...)
+	issues = append(issues, checkRuleTemplates(ruleNamespace)...)
+	if providerData.ruleConventionsSeverity == ruleSeverityError {
+		issues = append(issues, checkRuleConventions(ruleNamespace)...)
+	}
+	return append(issues, providerData.policy.checkRuleNamespace(ruleNamespace)...)
+}
+
+// parseExpression parses the expression with the query language of the backend, LogQL for Loki
+// and PromQL otherwise.
+func parseExpression(expr string, backend string) error {
+	if lintBackend(backend) == rules.LokiBackend {
+		_, err := syntax.ParseExpr(expr)
+		return err
+	}
+	_, err := parser.ParseExpr(expr)
+	return err
+}
+
+// checkRuleExpressions parses the expressions of every rule of the namespace, so that all of the
+// invalid ones are reported at once rather than by the ruler, group after group.
+func checkRuleExpressions(ruleNamespace rules.RuleNamespace, backend string) []ruleIssue {
+	var issues []ruleIssue
+	for _, group := range ruleNamespace.Groups {
+		for i, rule := range group.Rules {
+			if err := parseExpression(rule.Expr.Value, backend); err != nil {
+				issues = append(issues, newRuleIssue(ruleNamespace.Namespace, group, i, fmt.Errorf("expr: %w", err)))
+			}
+		}
+	}
+	return issues
+}
+
+// checkRuleGroups returns the definitions the ruler rejects, whatever the backend.
+func checkRuleGroups(ruleNamespace rules.RuleNamespace) []ruleIssue {
+	var issues []ruleIssue
+	groupNames := map[string]int{}
+	for _, group := range ruleNamespace.Groups {
+		groupNames[group.Name]++
+		if groupNames[group.Name] == 2 {
+			issues = append(issues, ruleIssue{
+				namespace: ruleNamespace.Namespace,
+				group:     group.Name,
+				err:       errors.New("the group is defined several times"),
+			})
+		}
+		if group.Limit < 0 {
+			issues = append(issues, ruleIssue{
+				namespace: ruleNamespace.Namespace,
+				group:     group.Name,
+				err:       fmt.Errorf("limit %d must not be negative", group.Limit),
+			})
+		}
+
+		ruleNames := map[string]int{}
+		for i, rule := range group.Rules {
+			name := ruleName(rule, i)
+			ruleNames[name]++
+			if ruleNames[name] == 2 {
+				issues = append(issues, newRuleIssue(ruleNamespace.Namespace, group, i,
+					errors.New("the rule name is used several times in the group")))
+			}
+			for _, err := range checkRule(rule) {
+				issues = append(issues, newRuleIssue(ruleNamespace.Namespace, group, i, err))
+			}
+		}
+	}
+	return issues
+}
+
+func checkRule(rule rulefmt.RuleNode) []error {
+	var errs []error
+	alert, record := rule.Alert.Value, rule.Record.Value
+	switch {
+	case alert != "" && record != "":
+		errs = append(errs, errors.New("only one of alert or record must be set"))
+	case alert == "" && record == "":
+		errs = append(errs, errors.New("one of alert or record must be set"))
+	case record != "":
+		if !model.IsValidMetricName(model.LabelValue(record)) {
+			errs = append(errs, fmt.Errorf("record: %q is not a valid metric name", record))
+		}
+		if rule.For != 0 {
+			errs = append(errs, errors.New("for: only valid for alerting rules"))
+		}
+		if rule.KeepFiringFor != 0 {
+			errs = append(errs, errors.New("keep_firing_for: only valid for alerting rules"))
+		}
+		if len(rule.Annotations) > 0 {
+			errs = append(errs, errors.New("annotations: only valid for alerting rules"))
+		}
+	}
+
+	for _, name := range sortedKeys(rule.Labels) {
+		if !model.LabelName(name).IsValid() {
+			errs = append(errs, fmt.Errorf("labels: %q is not a valid label name", name))
+		}
+	}
+	for _, name := range sortedKeys(rule.Annotations) {
+		if !model.LabelName(name).IsValid() {
+			errs = append(errs, fmt.Errorf("annotations: %q is not a valid annotation name", name))
+		}
+	}
+	return errs
+}
+
+// checkRuleConventions returns the recording rules not following the naming convention, which the
+// ruler accepts.
+func checkRuleConventions(ruleNamespace rules.RuleNamespace) []ruleIssue {
+	var issues []ruleIssue
+	for _, group := range ruleNamespace.Groups {
+		for i, rule := range group.Rules {
+			if record := rule.Record.Value; record != "" && rule.Alert.Value == "" &&
+				!recordingRuleNameRegexp.MatchString(record) {
+				issues = append(issues, newRuleIssue(ruleNamespace.Namespace, group, i,
+					errors.New("record: the name does not follow the level:metric:operations convention")))
+			}
+		}
+	}
+	return issues
+}
+
+// ruleConventionsDiagnostics returns a warning for each departure from the conventions when the
+// provider does not treat them as errors, in which case they already failed the plan.
+func ruleConventionsDiagnostics(ruleNamespace rules.RuleNamespace, meta any) diag.Diagnostics {
+	if meta.(*providerData).ruleConventionsSeverity != ruleSeverityWarning {
+		return nil
+	}
+	return ruleIssuesDiagnostics(checkRuleConventions(ruleNamespace), diag.Warning, nil)
+}
+
+// parseTemplate compiles a label or annotation template of an alert as the ruler does, so that the
+// broken templates are reported before the alert fires.
+func parseTemplate(name, text string) error {
+	expander := template.NewTemplateExpander(
+		context.Background(),
+		templateDefinitions+text,
+		name,
+		template.AlertTemplateData(map[string]string{}, map[string]string{}, "", 0),
+		model.Time(timestamp.FromTime(time.Now())),
+		nil,
+		nil,
+		nil,
+	)
+	return expander.ParseTest()
+}
+
+// checkRuleTemplates compiles the labels and annotations of the alerting rules, recording rules
+// labels are not templates.
+func checkRuleTemplates(ruleNamespace rules.RuleNamespace) []ruleIssue {
+	var issues []ruleIssue
+	for _, group := range ruleNamespace.Groups {
+		for i, rule := range group.Rules {
+			if rule.Alert.Value == "" {
+				continue
+			}
+			for _, name := range sortedKeys(rule.Labels) {
+				if err := parseTemplate(name, rule.Labels[name]); err != nil {
+					issues = append(issues, newRuleIssue(ruleNamespace.Namespace, group, i, fmt.Errorf("labels: %w", err)))
+				}
+			}
+			for _, name := range sortedKeys(rule.Annotations) {
+				if err := parseTemplate(name, rule.Annotations[name]); err != nil {
+					issues = append(issues, newRuleIssue(ruleNamespace.Namespace, group, i, fmt.Errorf("annotations: %w", err)))
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// validateRecordName rejects the invalid metric names, the naming convention depends on the
+// provider's rule_conventions_severity.
+func validateRecordName(i any, k cty.Path) diag.Diagnostics {
+	record := i.(string)
+	if record == "" {
+		return nil
+	}
+	if !model.IsValidMetricName(model.LabelValue(record)) {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Rule definition is not valid.",
+			Detail:        fmt.Sprintf("%q is not a valid metric name", record),
+			AttributePath: k,
+		}}
+	}
+	return nil
+}
+
+// validateAnnotations rejects the invalid annotation names and templates. Only alerts have annotations,
+// the labels templates are compiled at plan time as whether the rule is an alert is not known here.
+func validateAnnotations(i any, k cty.Path) diag.Diagnostics {
+	diags := validateLabelNames(i, k)
+	annotations := i.(map[string]any)
+	for _, name := range sortedKeys(annotations) {
+		text, ok := annotations[name].(string)
+		if !ok {
+			continue
+		}
+		if err := parseTemplate(name, text); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Rule definition is not valid.",
+				Detail:        err.Error(),
+				AttributePath: k.IndexString(name),
+			})
+		}
+	}
+	return diags
+}
+
+// validateLabelNames rejects the invalid label or annotation names of a map.
+func validateLabelNames(i any, k cty.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, name := range sortedKeys(i.(map[string]any)) {
+		if !model.LabelName(name).IsValid() {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Rule definition is not valid.",
+				Detail:        fmt.Sprintf("%q is not a valid name", name),
+				AttributePath: k.IndexString(name),
+			})
+		}
+	}
+	return diags
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := maps.Keys(m)
+	slices.Sort(keys)
+	return keys
+}
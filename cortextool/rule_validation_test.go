@@ -0,0 +1,216 @@
+package cortextool
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/cortex-tools/pkg/rules"
+	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"golang.org/x/exp/slices"
+)
+
+// testRuleNamespace returns a namespace holding a single group with the given rules.
+func testRuleNamespace(groupRules ...rulefmt.RuleNode) rules.RuleNamespace {
+	return rules.RuleNamespace{
+		Namespace: "ns",
+		Groups: []rwrulefmt.RuleGroup{
+			{RuleGroup: rulefmt.RuleGroup{Name: "group", Rules: groupRules}},
+		},
+	}
+}
+
+func testAlertRule(alert, expr string) rulefmt.RuleNode {
+	var rule rulefmt.RuleNode
+	rule.Alert.SetString(alert)
+	rule.Expr.SetString(expr)
+	return rule
+}
+
+func testRecordRule(record, expr string) rulefmt.RuleNode {
+	var rule rulefmt.RuleNode
+	rule.Record.SetString(record)
+	rule.Expr.SetString(expr)
+	return rule
+}
+
+func TestCheckRuleExpressions(t *testing.T) {
+	for _, tt := range []struct {
+		backend  string
+		valid    string
+		invalid  string
+		expected string
+	}{
+		{
+			backend:  backendLoki,
+			valid:    `sum(rate({job="app"} |= "error" [1m])) > 1`,
+			invalid:  `sum(rate({job="app"} |= "error" [1m])`,
+			expected: `namespace "ns", group "group", rule "Broken": expr: `,
+		},
+		{
+			backend:  backendCortex,
+			valid:    `sum(rate(http_requests_total[5m])) > 1`,
+			invalid:  `sum(rate(http_requests_total[5m]) >`,
+			expected: `namespace "ns", group "group", rule "job:broken:rate5m": expr: `,
+		},
+		{
+			backend:  backendMimir,
+			valid:    `up == 0`,
+			invalid:  `{job="app"} |= "error"`,
+			expected: `namespace "ns", group "group", rule "job:broken:rate5m": expr: `,
+		},
+	} {
+		t.Run(tt.backend, func(t *testing.T) {
+			broken := testAlertRule("Broken", tt.invalid)
+			if tt.backend != backendLoki {
+				broken = testRecordRule("job:broken:rate5m", tt.invalid)
+			}
+			namespace := testRuleNamespace(testAlertRule("Valid", tt.valid), broken, testAlertRule("AlsoValid", tt.valid))
+
+			issues := checkRuleExpressions(namespace, tt.backend)
+			if len(issues) != 1 {
+				t.Fatalf("got %d issues, expected 1: %v", len(issues), issues)
+			}
+			if !strings.HasPrefix(issues[0].Error(), tt.expected) {
+				t.Errorf("got %q, expected it to start with %q", issues[0].Error(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckRuleGroups(t *testing.T) {
+	both := testAlertRule("Both", `up == 0`)
+	both.Record.SetString("job:up:sum")
+	forRecord := testRecordRule("job:up:sum", `sum by (job) (up)`)
+	forRecord.For = model.Duration(time.Minute)
+	badLabels := testAlertRule("BadLabels", `up == 0`)
+	badLabels.Labels = map[string]string{"team": "sre", "team-name": "sre", "0severity": "page"}
+	badAnnotations := testAlertRule("BadAnnotations", `up == 0`)
+	badAnnotations.Annotations = map[string]string{"run book": "https://example.com"}
+
+	namespace := testRuleNamespace(
+		testAlertRule("Valid", `up == 0`),
+		testAlertRule("Valid", `up == 0`),
+		both,
+		testRecordRule("job:up-down:sum", `sum(up)`),
+		forRecord,
+		badLabels,
+		badAnnotations,
+	)
+	namespace.Groups = append(namespace.Groups, namespace.Groups[0], namespace.Groups[0])
+	namespace.Groups[1].Rules = nil
+	namespace.Groups[2].Rules = nil
+
+	var got []string
+	for _, issue := range checkRuleGroups(namespace) {
+		got = append(got, issue.Error())
+	}
+	expected := []string{
+		`namespace "ns", group "group", rule "Valid": the rule name is used several times in the group`,
+		`namespace "ns", group "group", rule "Both": only one of alert or record must be set`,
+		`namespace "ns", group "group", rule "job:up-down:sum": record: "job:up-down:sum" is not a valid metric name`,
+		`namespace "ns", group "group", rule "job:up:sum": for: only valid for alerting rules`,
+		`namespace "ns", group "group", rule "BadLabels": labels: "0severity" is not a valid label name`,
+		`namespace "ns", group "group", rule "BadLabels": labels: "team-name" is not a valid label name`,
+		`namespace "ns", group "group", rule "BadAnnotations": annotations: "run book" is not a valid annotation name`,
+		`namespace "ns", group "group": the group is defined several times`,
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestCheckRuleConventions(t *testing.T) {
+	namespace := testRuleNamespace(
+		testAlertRule("Down", `up == 0`),
+		testRecordRule("job:up:sum", `sum by (job) (up)`),
+		testRecordRule("up_sum", `sum(up)`),
+	)
+
+	var got []string
+	for _, issue := range checkRuleConventions(namespace) {
+		got = append(got, issue.Error())
+	}
+	expected := []string{
+		`namespace "ns", group "group", rule "up_sum": record: the name does not follow the level:metric:operations convention`,
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+
+	for severity, expected := range map[string]int{ruleSeverityWarning: 1, ruleSeverityError: 0} {
+		diags := ruleConventionsDiagnostics(namespace, &providerData{ruleConventionsSeverity: severity})
+		if len(diags) != expected {
+			t.Errorf("got %d diagnostics with the %s severity, expected %d", len(diags), severity, expected)
+		}
+	}
+}
+
+func TestValidateRecordName(t *testing.T) {
+	for record, expected := range map[string]diag.Severity{
+		"job:up:sum": -1,
+		"up_sum":     -1,
+		"job:up-sum": diag.Error,
+	} {
+		t.Run(record, func(t *testing.T) {
+			diags := validateRecordName(record, cty.GetAttrPath("record"))
+			if expected < 0 {
+				if len(diags) != 0 {
+					t.Errorf("got %v, expected no diagnostics", diags)
+				}
+				return
+			}
+			if len(diags) != 1 || diags[0].Severity != expected {
+				t.Errorf("got %v, expected a single diagnostic of severity %v", diags, expected)
+			}
+		})
+	}
+}
+
+func TestCheckRuleTemplates(t *testing.T) {
+	alert := testAlertRule("Down", `up == 0`)
+	alert.Labels = map[string]string{"team": "{{ $labels.team }}", "instance": "{{ $labels.instance"}
+	alert.Annotations = map[string]string{
+		"summary":     "{{ $labels.job }} is down since {{ $value | humanizeDuration }}",
+		"cluster":     "{{ $externalLabels.cluster }",
+		"description": "{{ .Unknown }}",
+	}
+	// Recording rules labels are not templates
+	record := testRecordRule("job:up:sum", `sum by (job) (up)`)
+	record.Labels = map[string]string{"literal": "{{ not a template"}
+
+	var got []string
+	for _, issue := range checkRuleTemplates(testRuleNamespace(alert, record)) {
+		got = append(got, issue.Error())
+	}
+	expected := []string{
+		`namespace "ns", group "group", rule "Down": labels: template: instance:1: unclosed action`,
+		`namespace "ns", group "group", rule "Down": annotations: template: cluster:1: unexpected "}" in operand`,
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestValidateAnnotations(t *testing.T) {
+	path := cty.GetAttrPath("group").IndexInt(0).GetAttr("rule").IndexInt(0).GetAttr("annotations")
+	diags := validateAnnotations(map[string]any{
+		"summary":   "{{ $labels.job }} is down",
+		"runbook":   "{{ $labels.job }",
+		"run book":  "https://example.com",
+		"dashboard": "https://example.com/d/{{ $externalLabels.cluster }}",
+	}, path)
+	if len(diags) != 2 {
+		t.Fatalf("got %d diagnostics, expected 2: %v", len(diags), diags)
+	}
+	if expected := path.IndexString("run book"); !diags[0].AttributePath.Equals(expected) {
+		t.Errorf("got path %#v for the invalid name, expected %#v", diags[0].AttributePath, expected)
+	}
+	if expected := path.IndexString("runbook"); !diags[1].AttributePath.Equals(expected) {
+		t.Errorf("got path %#v for the invalid template, expected %#v", diags[1].AttributePath, expected)
+	}
+}
@@ -0,0 +1,80 @@
+package cortextool
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/grafana/cortex-tools/pkg/rules"
+	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// stateFormatYaml stores the normalized rules
+	stateFormatYaml = "yaml"
+	// stateFormatSha256 stores the sha256 of the normalized rules
+	stateFormatSha256 = "sha256"
+	// stateFormatGroupSha256 stores the sha256 of each group's normalized rules, keyed by group name
+	stateFormatGroupSha256 = "group_sha256"
+)
+
+var stateFormats = []string{stateFormatYaml, stateFormatSha256, stateFormatGroupSha256}
+
+// providerStateFormat returns the state format matching the provider's store_rules_sha256.
+func providerStateFormat(storeRulesSha256 bool) string {
+	if storeRulesSha256 {
+		return stateFormatSha256
+	}
+	return stateFormatYaml
+}
+
+// resourceStateFormat returns the state format set on the resource, falling back to the provider's one.
+func resourceStateFormat(d attributeGetter, meta any) string {
+	if stateFormat := d.Get("state_format").(string); stateFormat != "" {
+		return stateFormat
+	}
+	return meta.(*providerData).stateFormat
+}
+
+// setDefaultStateFormat plans the provider's state format for the resource unless the resource overrides it.
+func setDefaultStateFormat(d *schema.ResourceDiff, meta any) error {
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.GetAttr("state_format").IsNull() {
+		return nil
+	}
+	if stateFormat := meta.(*providerData).stateFormat; d.Get("state_format").(string) != stateFormat {
+		return d.SetNew("state_format", stateFormat)
+	}
+	return nil
+}
+
+func sha256Hex(b []byte) string {
+	configHash := sha256.Sum256(b)
+	return fmt.Sprintf("%x", configHash[:])
+}
+
+// ruleGroupHashes returns the sha256 of each group's normalized YAML, keyed by group name.
+func ruleGroupHashes(groups []rwrulefmt.RuleGroup) map[string]string {
+	hashes := make(map[string]string, len(groups))
+	for _, group := range groups {
+		groupYamlBytes, _ := yaml.Marshal(&group)
+		hashes[group.Name] = sha256Hex(groupYamlBytes)
+	}
+	return hashes
+}
+
+// formatRuleNamespace returns the normalized rules as they are stored in the state.
+func formatRuleNamespace(ruleNamespace rules.RuleNamespace, stateFormat string) string {
+	switch stateFormat {
+	case stateFormatSha256:
+		newYamlBytes, _ := yaml.Marshal(&ruleNamespace)
+		return sha256Hex(newYamlBytes)
+	case stateFormatGroupSha256:
+		hashesYamlBytes, _ := yaml.Marshal(ruleGroupHashes(ruleNamespace.Groups))
+		return string(hashesYamlBytes)
+	default:
+		newYamlBytes, _ := yaml.Marshal(&ruleNamespace)
+		return string(newYamlBytes)
+	}
+}
@@ -0,0 +1,101 @@
+package cortextool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// tenantClient holds the clients bound to a tenant.
+type tenantClient struct {
+	cli   CortexRuleClient
+	amCli CortexAlertmanagerClient
+}
+
+// multiTenantClient is implemented by the injected clients able to serve other tenants than the
+// provider's one, such as the mock client.
+type multiTenantClient interface {
+	ForTenant(tenantID string) CortexRuleClient
+}
+
+// clientsForTenant returns the clients of the tenant, created on first use and then cached. They
+// are created outside the lock as the ruler API path may be probed, a concurrent creation for the
+// same tenant being discarded.
+func (c *providerData) clientsForTenant(ctx context.Context, tenantID string) (*tenantClient, error) {
+	c.tenantClientsMu.Lock()
+	tc, ok := c.tenantClients[tenantID]
+	c.tenantClientsMu.Unlock()
+	if ok {
+		return tc, nil
+	}
+
+	tc, err := c.newTenantClient(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("creating the client of tenant %q: %w", tenantID, err)
+	}
+
+	c.tenantClientsMu.Lock()
+	defer c.tenantClientsMu.Unlock()
+	if cached, ok := c.tenantClients[tenantID]; ok {
+		return cached, nil
+	}
+	c.tenantClients[tenantID] = tc
+	return tc, nil
+}
+
+// resourceTenantID returns the tenant set on the resource, falling back to the provider's one.
+func resourceTenantID(d attributeGetter, meta any) string {
+	if tenantID := d.Get("tenant_id").(string); tenantID != "" {
+		return tenantID
+	}
+	return meta.(*providerData).tenantID
+}
+
+// ruleClient returns the ruler client of the resource's tenant.
+func ruleClient(ctx context.Context, d attributeGetter, meta any) (CortexRuleClient, error) {
+	tc, err := meta.(*providerData).clientsForTenant(ctx, resourceTenantID(d, meta))
+	if err != nil {
+		return nil, err
+	}
+	return tc.cli, nil
+}
+
+// alertmanagerClient returns the Alertmanager client of the resource's tenant.
+func alertmanagerClient(ctx context.Context, d attributeGetter, meta any) (CortexAlertmanagerClient, error) {
+	tc, err := meta.(*providerData).clientsForTenant(ctx, resourceTenantID(d, meta))
+	if err != nil {
+		return nil, err
+	}
+	if tc.amCli == nil {
+		return nil, errors.New("the client does not support the Alertmanager API")
+	}
+	return tc.amCli, nil
+}
+
+// tenantResourceID prefixes the ID of a resource with its tenant when it overrides the provider's
+// one. The tenant is followed by a ":", which tenant IDs cannot contain.
+func tenantResourceID(d attributeGetter, id string) string {
+	if tenantID := d.Get("tenant_id").(string); tenantID != "" {
+		return tenantID + ":" + id
+	}
+	return id
+}
+
+// cutTenantID splits the tenant prefix off an imported ID, as set by tenantResourceID.
+func cutTenantID(id string) (tenantID, rest string, found bool) {
+	tenantID, rest, found = strings.Cut(id, ":")
+	if !found || tenantID == "" || strings.Contains(tenantID, "/") {
+		return "", id, false
+	}
+	return tenantID, rest, true
+}
+
+// setImportedTenantID sets the tenant of an imported resource, unless it is the provider's one.
+func setImportedTenantID(d *schema.ResourceData, meta any, tenantID string) {
+	if tenantID != meta.(*providerData).tenantID {
+		d.Set("tenant_id", tenantID)
+	}
+}
@@ -0,0 +1,70 @@
+package cortextool
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
+)
+
+// timeoutRuleClient bounds each call of a CortexRuleClient with the provider's request_timeout.
+type timeoutRuleClient struct {
+	client  CortexRuleClient
+	timeout time.Duration
+}
+
+func newTimeoutRuleClient(client CortexRuleClient, timeout time.Duration) *timeoutRuleClient {
+	return &timeoutRuleClient{
+		client:  client,
+		timeout: timeout,
+	}
+}
+
+func (c *timeoutRuleClient) CreateRuleGroup(ctx context.Context, namespace string, group rwrulefmt.RuleGroup) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.client.CreateRuleGroup(ctx, namespace, group)
+}
+
+func (c *timeoutRuleClient) DeleteRuleGroup(ctx context.Context, namespace string, groupName string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.client.DeleteRuleGroup(ctx, namespace, groupName)
+}
+
+func (c *timeoutRuleClient) ListRules(ctx context.Context, namespace string) (map[string][]rwrulefmt.RuleGroup, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.client.ListRules(ctx, namespace)
+}
+
+// timeoutAlertmanagerClient bounds each call of a CortexAlertmanagerClient with the provider's request_timeout.
+type timeoutAlertmanagerClient struct {
+	client  CortexAlertmanagerClient
+	timeout time.Duration
+}
+
+func newTimeoutAlertmanagerClient(client CortexAlertmanagerClient, timeout time.Duration) *timeoutAlertmanagerClient {
+	return &timeoutAlertmanagerClient{
+		client:  client,
+		timeout: timeout,
+	}
+}
+
+func (c *timeoutAlertmanagerClient) CreateAlertmanagerConfig(ctx context.Context, config string, templates map[string]string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.client.CreateAlertmanagerConfig(ctx, config, templates)
+}
+
+func (c *timeoutAlertmanagerClient) DeleteAlermanagerConfig(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.client.DeleteAlermanagerConfig(ctx)
+}
+
+func (c *timeoutAlertmanagerClient) GetAlertmanagerConfig(ctx context.Context) (string, map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.client.GetAlertmanagerConfig(ctx)
+}
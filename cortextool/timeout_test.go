@@ -0,0 +1,63 @@
+package cortextool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
+)
+
+// hangingRuleClient never answers before the context is done.
+type hangingRuleClient struct {
+	MockCortexRuleClient
+}
+
+func (hangingRuleClient) ListRules(ctx context.Context, _ string) (map[string][]rwrulefmt.RuleGroup, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (hangingRuleClient) GetAlertmanagerConfig(ctx context.Context) (string, map[string]string, error) {
+	<-ctx.Done()
+	return "", nil, ctx.Err()
+}
+
+func TestTimeoutRuleClient(t *testing.T) {
+	client := newTimeoutRuleClient(hangingRuleClient{NewMockCortexRuleClient(backendLoki)}, 10*time.Millisecond)
+
+	done := make(chan error)
+	go func() {
+		_, err := client.ListRules(context.Background(), "shared")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the request timeout was not applied")
+	}
+}
+
+func TestTimeoutAlertmanagerClient(t *testing.T) {
+	client := newTimeoutAlertmanagerClient(hangingRuleClient{NewMockCortexRuleClient(backendLoki)}, 10*time.Millisecond)
+
+	done := make(chan error)
+	go func() {
+		_, _, err := client.GetAlertmanagerConfig(context.Background())
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the request timeout was not applied")
+	}
+}
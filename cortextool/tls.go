@@ -0,0 +1,40 @@
+package cortextool
+
+import (
+	"os"
+
+	"github.com/grafana/dskit/crypto/tls"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// pemReader serves the inline PEM settings to tls.ClientConfig in place of the files it reads
+type pemReader map[string]string
+
+func (r pemReader) ReadSecret(path string) ([]byte, error) {
+	if pem, ok := r[path]; ok {
+		return []byte(pem), nil
+	}
+	return os.ReadFile(path)
+}
+
+// getTLSConfig returns the TLS configuration of the provider, the inline PEM settings being read
+// under their own name as path
+func getTLSConfig(d *schema.ResourceData) tls.ClientConfig {
+	reader := pemReader{}
+	path := func(pathKey, pemKey string) string {
+		if pem := d.Get(pemKey).(string); pem != "" {
+			reader[pemKey] = pem
+			return pemKey
+		}
+		return d.Get(pathKey).(string)
+	}
+
+	return tls.ClientConfig{
+		CAPath:             path("tls_ca_path", "tls_ca_pem"),
+		CertPath:           path("tls_cert_path", "tls_cert_pem"),
+		KeyPath:            path("tls_key_path", "tls_key_pem"),
+		ServerName:         d.Get("tls_server_name").(string),
+		InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
+		Reader:             reader,
+	}
+}
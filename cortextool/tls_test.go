@@ -0,0 +1,104 @@
+package cortextool
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	cryptotls "crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// generateCertificate returns a self-signed certificate valid for both the server and client
+// authentications, and its key, in PEM format
+func generateCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}))
+}
+
+func TestGetTLSConfigPEM(t *testing.T) {
+	certPEM, keyPEM := generateCertificate(t)
+	serverCert, err := cryptotls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM([]byte(certPEM))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &cryptotls.Config{
+		Certificates: []cryptotls.Certificate{serverCert},
+		ClientAuth:   cryptotls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, New("dev", nil)().Schema, map[string]any{
+		"tls_ca_pem":   certPEM,
+		"tls_cert_pem": certPEM,
+		"tls_key_pem":  keyPEM,
+	})
+	tlsClientConfig := getTLSConfig(d)
+	tlsConfig, err := tlsClientConfig.GetTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func TestGetTLSConfigServerName(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, New("dev", nil)().Schema, map[string]any{
+		"tls_server_name": "ruler.example.com",
+	})
+	tlsClientConfig := getTLSConfig(d)
+	tlsConfig, err := tlsClientConfig.GetTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.ServerName != "ruler.example.com" {
+		t.Errorf("got server name %q, expected ruler.example.com", tlsConfig.ServerName)
+	}
+}
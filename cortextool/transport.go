@@ -0,0 +1,53 @@
+package cortextool
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// newBaseTransport returns a copy of the transport built by the Cortex client with the provider's
+// proxy and connection settings applied
+func newBaseTransport(d *schema.ResourceData, clientTransport http.RoundTripper) http.RoundTripper {
+	transport, ok := clientTransport.(*http.Transport)
+	if !ok {
+		if clientTransport != nil {
+			// Leave alone a transport we do not know how to configure
+			return clientTransport
+		}
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+
+	proxyURL := d.Get("proxy_url").(string)
+	noProxy := d.Get("no_proxy").(string)
+	if proxyURL != "" || noProxy != "" {
+		transport.Proxy = proxyFunc(proxyURL, noProxy)
+	}
+	if maxIdleConns := d.Get("max_idle_conns").(int); maxIdleConns > 0 {
+		// All the requests go to the same host
+		transport.MaxIdleConns = maxIdleConns
+		transport.MaxIdleConnsPerHost = maxIdleConns
+	}
+
+	return transport
+}
+
+// proxyFunc returns the proxy selection of the environment overridden by the given settings
+func proxyFunc(proxyURL, noProxy string) func(*http.Request) (*url.URL, error) {
+	config := httpproxy.FromEnvironment()
+	if proxyURL != "" {
+		config.HTTPProxy = proxyURL
+		config.HTTPSProxy = proxyURL
+	}
+	if noProxy != "" {
+		config.NoProxy = noProxy
+	}
+	proxy := config.ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
@@ -0,0 +1,60 @@
+package cortextool
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestNewBaseTransport(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+	}))
+	defer proxy.Close()
+
+	d := schema.TestResourceDataRaw(t, New("dev", nil)().Schema, map[string]any{
+		"proxy_url":      proxy.URL,
+		"no_proxy":       "internal.example.com,10.0.0.0/8",
+		"max_idle_conns": 10,
+	})
+	transport := newBaseTransport(d, nil).(*http.Transport)
+
+	if transport.MaxIdleConnsPerHost != 10 {
+		t.Errorf("got %d idle connections per host, expected 10", transport.MaxIdleConnsPerHost)
+	}
+	if http.DefaultTransport.(*http.Transport).MaxIdleConnsPerHost == 10 {
+		t.Error("the default transport was modified")
+	}
+
+	for _, tt := range []struct {
+		url      string
+		expected bool
+	}{
+		{url: "http://ruler.example.com/api/v1/rules", expected: true},
+		{url: "https://ruler.example.com/api/v1/rules", expected: true},
+		{url: "http://internal.example.com/api/v1/rules", expected: false},
+		{url: "http://10.1.2.3/api/v1/rules", expected: false},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+		proxyURL, err := transport.Proxy(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (proxyURL != nil) != tt.expected {
+			t.Errorf("got proxy %v for %s, expected proxied: %t", proxyURL, tt.url, tt.expected)
+		}
+	}
+
+	client := http.Client{Transport: transport}
+	resp, err := client.Get("http://ruler.example.com/api/v1/rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(proxied) != 1 || proxied[0] != "http://ruler.example.com/api/v1/rules" {
+		t.Errorf("got proxied requests %v, expected the request to the ruler", proxied)
+	}
+}
@@ -2,11 +2,31 @@ package cortextool
 
 import (
 	"context"
+	"sync"
+
 	"github.com/grafana/cortex-tools/pkg/rules/rwrulefmt"
 )
 
 type providerData struct {
-	cli *CortexRuleClient
+	backend             string
+	tenantID            string
+	stateFormat         string
+	maxParallelRequests int
+	// ruleConventionsSeverity is either ruleSeverityWarning or ruleSeverityError
+	ruleConventionsSeverity string
+	// policy is nil when the provider does not set one
+	policy *rulePolicy
+
+	// newTenantClient creates the clients of a tenant
+	newTenantClient func(context.Context, string) (*tenantClient, error)
+	// tenantClients caches the clients of each tenant, starting with the provider's one
+	tenantClients   map[string]*tenantClient
+	tenantClientsMu sync.Mutex
+}
+
+// attributeGetter is implemented by both schema.ResourceData and schema.ResourceDiff
+type attributeGetter interface {
+	Get(string) any
 }
 
 type CortexRuleClient interface {
@@ -14,3 +34,9 @@ type CortexRuleClient interface {
 	DeleteRuleGroup(context.Context, string, string) error
 	ListRules(context.Context, string) (map[string][]rwrulefmt.RuleGroup, error)
 }
+
+type CortexAlertmanagerClient interface {
+	CreateAlertmanagerConfig(context.Context, string, map[string]string) error
+	DeleteAlermanagerConfig(context.Context) error
+	GetAlertmanagerConfig(context.Context) (string, map[string]string, error)
+}
@@ -0,0 +1,7 @@
+data "cortextool_rule_namespace" "demo" {
+  namespace = "demo"
+}
+
+output "demo_groups" {
+  value = data.cortextool_rule_namespace.demo.group_names
+}
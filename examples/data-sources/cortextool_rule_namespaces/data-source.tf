@@ -0,0 +1,7 @@
+data "cortextool_rule_namespaces" "all" {
+  name_regex = "^team-"
+}
+
+output "rule_groups" {
+  value = { for ns in data.cortextool_rule_namespaces.all.namespaces : ns.name => ns.group_names }
+}
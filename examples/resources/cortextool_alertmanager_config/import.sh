@@ -0,0 +1,4 @@
+# The Alertmanager configuration is imported as "alertmanager"
+terraform import cortextool_alertmanager_config.demo alertmanager
+# Prefix the ID with "<tenant>:" to manage the configuration of another tenant, setting tenant_id
+terraform import cortextool_alertmanager_config.demo team-a:alertmanager
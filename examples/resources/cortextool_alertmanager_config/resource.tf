@@ -0,0 +1,7 @@
+resource "cortextool_alertmanager_config" "demo" {
+  # See cortextool/testdata/alertmanager.yaml
+  config_yaml = file("testdata/alertmanager.yaml")
+  template_files = {
+    "default.tmpl" = file("templates/default.tmpl")
+  }
+}
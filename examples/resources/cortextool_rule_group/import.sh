@@ -0,0 +1,4 @@
+# Rule groups are imported by namespace and group name
+terraform import cortextool_rule_group.sre shared/sre
+# Prefix the ID with "<tenant>:" to manage the group of another tenant, setting tenant_id
+terraform import cortextool_rule_group.sre team-a:shared/sre
@@ -0,0 +1,6 @@
+resource "cortextool_rule_group" "sre" {
+  namespace = "shared"
+  name      = "sre"
+  # See cortextool/testdata/rule_group.yaml
+  config_yaml = file("testdata/rule_group.yaml")
+}
@@ -0,0 +1,5 @@
+# Rule namespaces are imported by name, optionally prefixed with the provider's tenant
+terraform import cortextool_rule_namespace.demo demo
+terraform import cortextool_rule_namespace.demo tenant/demo
+# Prefix the name with "<tenant>:" to manage the namespace of another tenant, setting tenant_id
+terraform import cortextool_rule_namespace.demo team-a:demo
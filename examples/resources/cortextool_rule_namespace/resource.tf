@@ -3,3 +3,21 @@ resource "cortextool_rule_namespace" "demo" {
   # See cortextool/testsdata/rules.yaml
   config_yaml = file("testdata/rules.yaml")
 }
+
+resource "cortextool_rule_namespace" "blocks" {
+  namespace = "blocks"
+
+  group {
+    name     = "grafana-agent"
+    interval = "1m"
+
+    rule {
+      alert = "LogWarnMessages"
+      expr  = "sum(rate({deployment=\"grafana-agent-traces\"} |= `level=warn` [1m])) > 0.1"
+      for   = "5m"
+      labels = {
+        team = "sre"
+      }
+    }
+  }
+}
@@ -6,7 +6,11 @@ require (
 	github.com/grafana/cortex-tools v0.11.4-0.20251128063340-e339c37a034f
 	github.com/hashicorp/terraform-plugin-docs v0.25.0
 	github.com/hashicorp/terraform-plugin-sdk/v2 v2.40.1
+	github.com/prometheus/alertmanager v0.26.0
+	github.com/prometheus/common/sigv4 v0.1.0
 	golang.org/x/exp v0.0.0-20230713183714-613f0c0eb8a1
+	golang.org/x/net v0.55.0
+	golang.org/x/oauth2 v0.27.0
 )
 
 require (
@@ -72,8 +76,7 @@ require (
 	github.com/posener/complete v1.2.3 // indirect
 	github.com/prometheus/client_golang v1.16.0 // indirect
 	github.com/prometheus/client_model v0.6.0 // indirect
-	github.com/prometheus/common v0.44.0 // indirect
-	github.com/prometheus/common/sigv4 v0.1.0 // indirect
+	github.com/prometheus/common v0.44.0
 	github.com/prometheus/procfs v0.11.1 // indirect
 	github.com/shopspring/decimal v1.3.1 // indirect
 	github.com/sirupsen/logrus v1.9.3 // indirect
@@ -84,8 +87,6 @@ require (
 	go.uber.org/atomic v1.11.0 // indirect
 	go.uber.org/goleak v1.3.0 // indirect
 	golang.org/x/crypto v0.52.0 // indirect
-	golang.org/x/net v0.55.0 // indirect
-	golang.org/x/oauth2 v0.27.0 // indirect
 	golang.org/x/sync v0.20.0 // indirect
 	golang.org/x/sys v0.45.0 // indirect
 	golang.org/x/text v0.37.0 // indirect
@@ -97,7 +98,11 @@ require (
 	gopkg.in/yaml.v3 v3.0.1
 )
 
-require github.com/grafana/dskit v0.0.0-20230908075806-579cf66fbf9b
+require (
+	github.com/grafana/dskit v0.0.0-20230908075806-579cf66fbf9b
+	github.com/grafana/loki v1.6.2-0.20230905071424-a60c1777ce15
+	github.com/prometheus/prometheus v1.8.2-0.20220411232225-ce6a643ee88f
+)
 
 require (
 	cel.dev/expr v0.15.0 // indirect
@@ -194,7 +199,6 @@ require (
 	github.com/googleapis/gax-go/v2 v2.12.0 // indirect
 	github.com/gorilla/mux v1.8.0 // indirect
 	github.com/grafana/gomemcache v0.0.0-20230316202710-a081dae0aba9 // indirect
-	github.com/grafana/loki v1.6.2-0.20230905071424-a60c1777ce15 // indirect
 	github.com/grafana/loki/pkg/push v0.0.0-20230904150506-087b21fa5ec6 // indirect
 	github.com/grafana/regexp v0.0.0-20221122212121-6b5c0a4cb7fd // indirect
 	github.com/grpc-ecosystem/go-grpc-middleware v1.4.0 // indirect
@@ -235,9 +239,7 @@ require (
 	github.com/pierrec/lz4/v4 v4.1.18 // indirect
 	github.com/pkg/browser v0.0.0-20240102092130-5ac0b6a4141c // indirect
 	github.com/planetscale/vtprotobuf v0.6.1-0.20240319094008-0393e58bdf10 // indirect
-	github.com/prometheus/alertmanager v0.26.0 // indirect
 	github.com/prometheus/exporter-toolkit v0.10.1-0.20230714054209-2f4150c63f97 // indirect
-	github.com/prometheus/prometheus v1.8.2-0.20220411232225-ce6a643ee88f // indirect
 	github.com/rs/xid v1.5.0 // indirect
 	github.com/sean-/seed v0.0.0-20170313163322-e2103e2c3529 // indirect
 	github.com/sercand/kuberesolver/v4 v4.0.0 // indirect